@@ -0,0 +1,98 @@
+package uds
+
+import (
+	"context"
+	"github.com/go-resty/resty/v2"
+)
+
+// CustomersAPI
+// Методы работы с клиентами.
+type CustomersAPI interface {
+	CustomerGetList(maxValue int, offset int) (*List[Customer], *resty.Response, error)
+	CustomerGetListWithContext(ctx context.Context, maxValue int, offset int) (*List[Customer], *resty.Response, error)
+	CustomerFindByCode(code string, params *FindCustomerParams) (*FindCustomerResponse, *resty.Response, error)
+	CustomerFindByCodeWithContext(ctx context.Context, code string, params *FindCustomerParams) (*FindCustomerResponse, *resty.Response, error)
+	CustomerFindByPhone(phone string, params *FindCustomerParams) (*FindCustomerResponse, *resty.Response, error)
+	CustomerFindByPhoneWithContext(ctx context.Context, phone string, params *FindCustomerParams) (*FindCustomerResponse, *resty.Response, error)
+	CustomerFindByUID(uid string, params *FindCustomerParams) (*FindCustomerResponse, *resty.Response, error)
+	CustomerFindByUIDWithContext(ctx context.Context, uid string, params *FindCustomerParams) (*FindCustomerResponse, *resty.Response, error)
+	CustomerGetByID(id int64) (*CustomerDetail, *resty.Response, error)
+	CustomerGetByIDWithContext(ctx context.Context, id int64) (*CustomerDetail, *resty.Response, error)
+	CustomerGetTags(id int64) (*CustomerTagList, *resty.Response, error)
+	CustomerGetTagsWithContext(ctx context.Context, id int64) (*CustomerTagList, *resty.Response, error)
+	CustomerSetTags(id int64, tagsReq SetCustomerTagsRequest) (*List[TagModel], *resty.Response, error)
+	CustomerSetTagsWithContext(ctx context.Context, id int64, tagsReq SetCustomerTagsRequest) (*List[TagModel], *resty.Response, error)
+}
+
+// OperationsAPI
+// Методы работы с операциями.
+type OperationsAPI interface {
+	OperationGetList(maxValue int, cursor string) (*OperationList, *resty.Response, error)
+	OperationGetListWithContext(ctx context.Context, maxValue int, cursor string) (*OperationList, *resty.Response, error)
+	OperationCreate(operation *CreateOperationRequest) (*CreateOperationResponse, *resty.Response, error)
+	OperationCreateWithContext(ctx context.Context, operation *CreateOperationRequest) (*CreateOperationResponse, *resty.Response, error)
+	OperationGetByID(id int64) (*Operation, *resty.Response, error)
+	OperationGetByIDWithContext(ctx context.Context, id int64) (*Operation, *resty.Response, error)
+	OperationRefund(id int64, partialAmount float64) (*Operation, *resty.Response, error)
+	OperationRefundWithContext(ctx context.Context, id int64, partialAmount float64) (*Operation, *resty.Response, error)
+	OperationCalc(operation *CalcOperationRequest) (*CalcOperationResponse, *resty.Response, error)
+	OperationCalcWithContext(ctx context.Context, operation *CalcOperationRequest) (*CalcOperationResponse, *resty.Response, error)
+	OperationReward(operation RewardOperationRequest) (*RewardOperationResponse, *resty.Response, error)
+	OperationRewardWithContext(ctx context.Context, operation RewardOperationRequest) (*RewardOperationResponse, *resty.Response, error)
+}
+
+// GoodsOrdersAPI
+// Методы работы с заказами товаров.
+type GoodsOrdersAPI interface {
+	GoodsOrderGetByID(id int64) (*GoodsOrderDetailed, *resty.Response, error)
+	GoodsOrderGetByIDWithContext(ctx context.Context, id int64) (*GoodsOrderDetailed, *resty.Response, error)
+	GoodsOrderGetList(maxValue int, offset int) (*GoodsOrderList, *resty.Response, error)
+	GoodsOrderGetListWithContext(ctx context.Context, maxValue int, offset int) (*GoodsOrderList, *resty.Response, error)
+	GoodsOrderUpdateItems(id int64, updatedOrder *UpdateGoodsOrderRequest[GoodsOrderItemUpdate]) (*GoodsOrderDetailed, *resty.Response, error)
+	GoodsOrderUpdateItemsWithContext(ctx context.Context, id int64, updatedOrder *UpdateGoodsOrderRequest[GoodsOrderItemUpdate]) (*GoodsOrderDetailed, *resty.Response, error)
+	GoodsOrderAddItems(id int64, updatedOrder *UpdateGoodsOrderRequest[GoodsOrderItemNew]) (*GoodsOrderDetailed, *resty.Response, error)
+	GoodsOrderAddItemsWithContext(ctx context.Context, id int64, updatedOrder *UpdateGoodsOrderRequest[GoodsOrderItemNew]) (*GoodsOrderDetailed, *resty.Response, error)
+	GoodsOrderComplete(id int64) (*CompleteGoodsOrder, *resty.Response, error)
+	GoodsOrderCompleteWithContext(ctx context.Context, id int64) (*CompleteGoodsOrder, *resty.Response, error)
+	GoodsOrderCancel(id int64, reason string) (*GoodsOrderDetailed, *resty.Response, error)
+	GoodsOrderCancelWithContext(ctx context.Context, id int64, reason string) (*GoodsOrderDetailed, *resty.Response, error)
+	GoodsOrderSetState(id int64, state GoodsOrderState) (*GoodsOrderDetailed, *resty.Response, error)
+	GoodsOrderSetStateWithContext(ctx context.Context, id int64, state GoodsOrderState) (*GoodsOrderDetailed, *resty.Response, error)
+	GoodsOrderGenerateCode(id int64) (string, *resty.Response, error)
+	GoodsOrderGenerateCodeWithContext(ctx context.Context, id int64) (string, *resty.Response, error)
+}
+
+// GoodsAPI
+// Методы работы с каталогом товаров.
+type GoodsAPI interface {
+	GoodsGetList(maxValue int, offset int, nodeId int64) (*GoodsList, *resty.Response, error)
+	GoodsGetListWithContext(ctx context.Context, maxValue int, offset int, nodeId int64) (*GoodsList, *resty.Response, error)
+	GoodsGetByID(id int64) (*GoodsNode, *resty.Response, error)
+	GoodsGetByIDWithContext(ctx context.Context, id int64) (*GoodsNode, *resty.Response, error)
+	GoodsCreate(node *GoodsNodeRequest) (*GoodsNode, *resty.Response, error)
+	GoodsCreateWithContext(ctx context.Context, node *GoodsNodeRequest) (*GoodsNode, *resty.Response, error)
+	GoodsUpdate(id int64, node *GoodsNodeRequest) (*GoodsNode, *resty.Response, error)
+	GoodsUpdateWithContext(ctx context.Context, id int64, node *GoodsNodeRequest) (*GoodsNode, *resty.Response, error)
+	GoodsDelete(id int64) (*resty.Response, error)
+	GoodsDeleteWithContext(ctx context.Context, id int64) (*resty.Response, error)
+}
+
+// SettingsAPI
+// Методы работы с настройками компании.
+type SettingsAPI interface {
+	SettingsGet() (*Settings, *resty.Response, error)
+	SettingsGetWithContext(ctx context.Context) (*Settings, *resty.Response, error)
+}
+
+// API
+// Полный набор методов клиента UDS. Реализуется *Client и udsmock.Mock,
+// что позволяет подменять клиента в тестах.
+type API interface {
+	CustomersAPI
+	OperationsAPI
+	GoodsOrdersAPI
+	GoodsAPI
+	SettingsAPI
+}
+
+var _ API = (*Client)(nil)
@@ -0,0 +1,137 @@
+package uds
+
+import "math/big"
+
+// CalculatePurchase
+// Рассчитывает операцию без обращения к API, например, когда API недоступно,
+// а кассе нужно показать ожидаемую скидку и кешбэк. Результат соответствует ответу OperationCalc
+// при тех же настройках компании, статусе и балансе клиента.
+//
+// Процент скидки или кешбэка берется из индивидуального процента клиента
+// (Participant.DiscountRate или Participant.CashbackRate), если он задан, иначе из статуса клиента.
+// Денежные суммы округляются до сотых по RoundHalfUp, баллы - вниз.
+// Если receipt.Points равно nil, списывается максимально доступное количество баллов.
+// Если participant равно nil, используется базовый статус, а баланс клиента не ограничивает MaxPoints.
+// Возвращает ошибку, если сумма в чеке, баланс или процент равны NaN, бесконечности
+// или результат не помещается в Money или Points (ErrDecimalOverflow).
+func CalculatePurchase(settings *Settings, participant *Participant, receipt CalcOperationReceipt) (PurchaseDetail, error) {
+	detail, err := CalculatePurchaseExact(settings, participant, receipt)
+	if err != nil {
+		return PurchaseDetail{}, err
+	}
+	return detail.PurchaseDetail(), nil
+}
+
+// CalculatePurchaseExact
+// CalculatePurchase с точными суммами.
+func CalculatePurchaseExact(settings *Settings, participant *Participant, receipt CalcOperationReceipt) (ExactPurchaseDetail, error) {
+	if settings == nil {
+		settings = &Settings{}
+	}
+
+	tier := membershipTier(settings, participant)
+	var c exactConverter
+	exact := ExactReceipt{
+		Total:             c.money("receipt.total", receipt.Total),
+		SkipLoyaltyTotal:  c.moneyPtr("receipt.skipLoyaltyTotal", receipt.SkipLoyaltyTotal),
+		UnredeemableTotal: c.moneyPtr("receipt.unredeemableTotal", receipt.UnredeemableTotal),
+	}
+
+	var spend, balance Points
+	if receipt.Points != nil {
+		spend = c.points("receipt.points", max(0, *receipt.Points))
+	}
+	if participant != nil {
+		balance = c.points("participant.points", max(0, participant.Points))
+	}
+	if c.err != nil {
+		return ExactPurchaseDetail{}, c.err
+	}
+
+	discountAmount, err := receiptDiscount(settings.BaseDiscountPolicy, tier, exact)
+	if err != nil {
+		return ExactPurchaseDetail{}, err
+	}
+
+	maxPoints, err := receiptPointsLimit(tier, exact, discountAmount)
+	if err != nil {
+		return ExactPurchaseDetail{}, err
+	}
+	if participant != nil {
+		maxPoints = maxPoints.Min(balance)
+	}
+
+	if receipt.Points != nil {
+		spend = spend.Min(maxPoints)
+	} else {
+		spend = maxPoints
+	}
+
+	skipLoyaltyTotal := valueOrZeroMoney(exact.SkipLoyaltyTotal)
+	cashBack, err := purchaseCashback(settings.BaseDiscountPolicy, tier, exact.Total, skipLoyaltyTotal, spend)
+	if err != nil {
+		return ExactPurchaseDetail{}, err
+	}
+
+	cash := exact.Total.Sub(discountAmount).Sub(spend.Money())
+	netDiscount := discountAmount.Add(spend.Money())
+
+	detail := ExactPurchaseDetail{
+		MaxPoints:          maxPoints,
+		Total:              exact.Total,
+		SkipLoyaltyTotal:   skipLoyaltyTotal,
+		UnredeemableTotal:  valueOrZeroMoney(exact.UnredeemableTotal),
+		DiscountAmount:     discountAmount,
+		Points:             spend,
+		PointsPercent:      percentOf(spend.Minor(), exact.Total.Minor()),
+		NetDiscount:        netDiscount,
+		NetDiscountPercent: percentOf(netDiscount.Minor(), exact.Total.Minor()),
+		Cash:               cash,
+		CashTotal:          cash,
+		CashBack:           cashBack,
+		MaxScoresDiscount:  tier.MaxScoresDiscount,
+	}
+
+	if settings.BaseDiscountPolicy == DiscountPolicyApplyDiscount {
+		detail.DiscountPercent = tier.Rate
+	}
+
+	return detail, nil
+}
+
+// purchaseCashback
+// Начисляемые баллы для CHARGE_SCORES: процент статуса от части счета,
+// оплаченной деньгами и участвующей в программе лояльности.
+func purchaseCashback(policy DiscountPolicy, tier MembershipTier, total, skipLoyaltyTotal Money, points Points) (Points, error) {
+	if policy == DiscountPolicyApplyDiscount {
+		return Points{}, nil
+	}
+
+	base := total.Sub(skipLoyaltyTotal).Sub(points.Money())
+	if base.Sign() <= 0 {
+		return Points{}, nil
+	}
+
+	return base.Points().Percent(tier.Rate)
+}
+
+// percentOf
+// Доля part от total в процентах, округленная до сотых.
+func percentOf(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	value := big.NewRat(part, total)
+	value.Mul(value, big.NewRat(100*minorUnits, 1))
+
+	minor, _ := roundRat(value, RoundHalfUp)
+	return minorToFloat(minor)
+}
+
+func valueOrZeroMoney(value *Money) Money {
+	if value == nil {
+		return Money{}
+	}
+	return *value
+}
@@ -0,0 +1,167 @@
+package uds_test
+
+import (
+	"fmt"
+	"github.com/arcsub/go-uds/uds"
+	"github.com/arcsub/go-uds/uds/udstest"
+	"testing"
+)
+
+// calcCase
+// Расчет операции для клиента: ответ OperationCalc записан в кассету
+// и сравнивается с результатом uds.CalculatePurchase для того же клиента.
+// participant задает клиента при записи с сервера udstest (-record-fake), при записи с UDS (-record)
+// используется клиент песочницы из UDS_TEST_CALC_UIDS, и название случая описывает только чек.
+type calcCase struct {
+	name        string
+	participant uds.Participant
+	receipt     uds.CalcOperationReceipt
+}
+
+var goldTier = uds.MembershipTier{Uid: "gold", Name: "Золотой", Rate: 10, MaxScoresDiscount: 30}
+
+func ptr(value float64) *float64 {
+	return &value
+}
+
+var calcCases = []calcCase{
+	{
+		name:        "points limited by balance",
+		participant: uds.Participant{Points: 300},
+		receipt:     uds.CalcOperationReceipt{Total: 1000},
+	},
+	{
+		name:        "points limited by max scores discount",
+		participant: uds.Participant{Points: 1000},
+		receipt:     uds.CalcOperationReceipt{Total: 1000},
+	},
+	{
+		name:        "skip loyalty and unredeemable totals",
+		participant: uds.Participant{Points: 1000},
+		receipt:     uds.CalcOperationReceipt{Total: 1000, SkipLoyaltyTotal: ptr(200), UnredeemableTotal: ptr(300)},
+	},
+	{
+		name:        "requested points",
+		participant: uds.Participant{Points: 1000},
+		receipt:     uds.CalcOperationReceipt{Total: 1000, Points: ptr(123.45)},
+	},
+	{
+		name:        "requested points above limit",
+		participant: uds.Participant{Points: 1000},
+		receipt:     uds.CalcOperationReceipt{Total: 500, Points: ptr(400)},
+	},
+	{
+		name:        "fractional amounts",
+		participant: uds.Participant{Points: 77.77},
+		receipt:     uds.CalcOperationReceipt{Total: 333.33, SkipLoyaltyTotal: ptr(10.01)},
+	},
+	{
+		name:        "fractional limit",
+		participant: uds.Participant{Points: 1000},
+		receipt:     uds.CalcOperationReceipt{Total: 99.99, UnredeemableTotal: ptr(0.03)},
+	},
+	{
+		name:        "membership tier",
+		participant: uds.Participant{Points: 1000, MembershipTier: goldTier},
+		receipt:     uds.CalcOperationReceipt{Total: 1234.56},
+	},
+	{
+		name:        "individual cashback rate",
+		participant: uds.Participant{Points: 50, CashbackRate: 7},
+		receipt:     uds.CalcOperationReceipt{Total: 999.99},
+	},
+	{
+		name:        "individual discount rate",
+		participant: uds.Participant{Points: 50, DiscountRate: 12},
+		receipt:     uds.CalcOperationReceipt{Total: 999.99},
+	},
+	{
+		name:        "zero balance",
+		participant: uds.Participant{},
+		receipt:     uds.CalcOperationReceipt{Total: 450.5},
+	},
+}
+
+// TestCalculatePurchaseMatchesOperationCalc
+// Сверяет uds.CalculatePurchase с ответами OperationCalc из кассет calc_<policy>.json.
+// Источник ответов указан в поле source кассеты. Проверка соответствия округлению UDS имеет смысл
+// только для кассет, записанных с UDS (-record): кассеты, записанные с udstest (-record-fake),
+// показывают лишь совпадение двух реализаций в этом репозитории.
+func TestCalculatePurchaseMatchesOperationCalc(t *testing.T) {
+	for _, policy := range []uds.DiscountPolicy{uds.DiscountPolicyChargeScores, uds.DiscountPolicyApplyDiscount} {
+		t.Run(string(policy), func(t *testing.T) {
+			client, f := recordingClient(t, "calc_"+string(policy),
+				func() fixture {
+					f := envFixture(t, "UDS_TEST_CALC_UIDS")
+					if len(f.CalcUIDs) != len(calcCases) {
+						t.Fatalf("UDS_TEST_CALC_UIDS has %d uids, want %d", len(f.CalcUIDs), len(calcCases))
+					}
+
+					// Политика скидок - настройка компании, поэтому с UDS записывается только кассета ее политики.
+					settings, _, err := envClient(t).SettingsGet()
+					if err != nil {
+						t.Fatal(err)
+					}
+					if settings.BaseDiscountPolicy != policy {
+						t.Skipf("the test company uses %s", settings.BaseDiscountPolicy)
+					}
+					return f
+				},
+				func(server *udstest.Server) fixture {
+					settings := udstest.DefaultSettings()
+					settings.BaseDiscountPolicy = policy
+					settings.LoyaltyProgramSettings.MembershipTiers = append(settings.LoyaltyProgramSettings.MembershipTiers, goldTier)
+					server.SetSettings(settings)
+
+					var f fixture
+					for i, c := range calcCases {
+						participant := c.participant
+						participant.Id = int64(3000001 + i)
+						server.AddCustomer(uds.Customer{Uid: calcUID(i), Participant: participant})
+						f.CalcUIDs = append(f.CalcUIDs, calcUID(i))
+					}
+					return f
+				},
+			)
+
+			settings, _, err := client.SettingsGet()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, c := range calcCases {
+				found, _, err := client.CustomerFindByUID(f.CalcUIDs[i], nil)
+				if err != nil {
+					t.Fatalf("%s: %v", c.name, err)
+				}
+
+				req := &uds.CalcOperationRequest{
+					Participant: (&uds.ParticipantShort{}).SetUid(f.CalcUIDs[i]),
+					Receipt:     c.receipt,
+				}
+
+				resp, _, err := client.OperationCalc(req)
+				if err != nil {
+					t.Fatalf("%s: %v", c.name, err)
+				}
+
+				participant := found.User.Participant
+				if participant.MembershipTier.Uid == "" {
+					participant.MembershipTier = settings.LoyaltyProgramSettings.BaseMembershipTier
+				}
+
+				got, err := uds.CalculatePurchase(settings, &participant, c.receipt)
+				if err != nil {
+					t.Fatalf("%s: %v", c.name, err)
+				}
+				if got != resp.Purchase {
+					t.Errorf("%s:\n got  %+v\n want %+v", c.name, got, resp.Purchase)
+				}
+			}
+		})
+	}
+}
+
+func calcUID(i int) string {
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", i+1)
+}
@@ -0,0 +1,552 @@
+// Package catalogsync
+// Синхронизация каталога товаров UDS с внешним каталогом (ERP, учетной системой).
+// Желаемое дерево каталога берется из ProductSource, сравнивается с каталогом UDS
+// по externalId, и недостающие элементы создаются, измененные - обновляются,
+// а лишние - удаляются. Категории создаются раньше вложенных в них товаров.
+// Элементы каталога UDS без externalId не изменяются.
+//
+// Источнику принадлежат название, признак hidden, родительская категория, тип
+// и заполненные в источнике поля данных. Незаполненные поля (акционная цена, остаток,
+// шаг количества и т.п.) при обновлении берутся из каталога UDS, поэтому
+// значения, заданные в UDS, не затираются и не считаются изменением.
+//
+//	syncer := catalogsync.New(client, catalogsync.WithDryRun(true))
+//	report, err := syncer.Sync(ctx, catalogsync.CSVFile("catalog.csv"))
+//	fmt.Println(report)
+package catalogsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/arcsub/go-uds/uds"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Syncer
+// Синхронизация каталога через GoodsAPI.
+type Syncer struct {
+	goods       uds.GoodsAPI
+	dryRun      bool
+	keepMissing bool
+	hook        func(Change)
+}
+
+// Option
+// Функциональная опция для New
+type Option func(*Syncer)
+
+// WithDryRun
+// Только рассчитать изменения, не изменяя каталог UDS.
+func WithDryRun(dryRun bool) Option {
+	return func(s *Syncer) {
+		s.dryRun = dryRun
+	}
+}
+
+// WithKeepMissing
+// Не удалять из UDS элементы с externalId, отсутствующие в источнике.
+func WithKeepMissing(keepMissing bool) Option {
+	return func(s *Syncer) {
+		s.keepMissing = keepMissing
+	}
+}
+
+// WithHook
+// Функция, вызываемая после каждого изменения (или его расчета в режиме WithDryRun).
+func WithHook(hook func(Change)) Option {
+	return func(s *Syncer) {
+		s.hook = hook
+	}
+}
+
+// New
+// Создает синхронизацию каталога через goods.
+func New(goods uds.GoodsAPI, opts ...Option) *Syncer {
+	s := &Syncer{goods: goods}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Sync
+// Приводит каталог UDS к дереву из source. Изменения применяются в порядке:
+// категории (родительские раньше вложенных), изменения товаров, удаления
+// (товары раньше категорий), создание товаров. Удаления выполняются до создания товаров,
+// чтобы освободить место при лимите количества товаров.
+//
+// При ErrGoodsLimitIsReached оставшиеся элементы не создаются, а Report.LimitReached равно true.
+// Ошибки отдельных изменений сохраняются в отчете и не прерывают синхронизацию.
+// Ошибка возвращается, если не удалось прочитать источник или каталог UDS
+// либо дерево из источника некорректно (повторяющийся externalId, неизвестная родительская категория).
+func (s *Syncer) Sync(ctx context.Context, source ProductSource) (*Report, error) {
+	products, err := source.Products(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("catalogsync: read source: %w", err)
+	}
+
+	ordered, err := orderProducts(products)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := s.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	run := newSyncRun(s, remote)
+
+	for _, product := range ordered {
+		if product.Data.Type == uds.GoodsItemTypeCategory {
+			run.upsert(ctx, product)
+		}
+	}
+
+	for _, product := range ordered {
+		if product.Data.Type != uds.GoodsItemTypeCategory && run.byExternalId[product.ExternalId] != nil {
+			run.upsert(ctx, product)
+		}
+	}
+
+	if !s.keepMissing {
+		run.deleteMissing(ctx, ordered)
+	}
+
+	for _, product := range ordered {
+		if product.Data.Type != uds.GoodsItemTypeCategory && run.byExternalId[product.ExternalId] == nil {
+			run.upsert(ctx, product)
+		}
+	}
+
+	return run.report, nil
+}
+
+// fetch
+// Все элементы каталога UDS: обход дерева от корня с постраничным чтением каждой категории.
+func (s *Syncer) fetch(ctx context.Context) ([]uds.GoodsNode, error) {
+	var nodes []uds.GoodsNode
+	parents := []int64{0}
+
+	for len(parents) > 0 {
+		parent := parents[0]
+		parents = parents[1:]
+
+		for offset := 0; ; {
+			list, _, err := s.goods.GoodsGetListWithContext(ctx, uds.GoodsListMaxPageSize, offset, parent)
+			if err != nil {
+				return nil, fmt.Errorf("catalogsync: list goods: %w", err)
+			}
+
+			for _, node := range list.Rows {
+				nodes = append(nodes, node)
+				if node.Data.Type == uds.GoodsItemTypeCategory {
+					parents = append(parents, node.Id)
+				}
+			}
+
+			offset += len(list.Rows)
+			if len(list.Rows) < uds.GoodsListMaxPageSize || (list.Total > 0 && offset >= list.Total) {
+				break
+			}
+		}
+	}
+
+	return nodes, nil
+}
+
+// orderProducts
+// Проверяет дерево и упорядочивает элементы так, чтобы родительские категории шли раньше вложенных.
+func orderProducts(products []Product) ([]Product, error) {
+	byExternalId := make(map[string]Product, len(products))
+	for _, product := range products {
+		if product.ExternalId == "" {
+			return nil, fmt.Errorf("catalogsync: product %q has no externalId", product.Name)
+		}
+		if _, ok := byExternalId[product.ExternalId]; ok {
+			return nil, fmt.Errorf("catalogsync: duplicate externalId %q", product.ExternalId)
+		}
+		byExternalId[product.ExternalId] = product
+	}
+
+	depth := make(map[string]int, len(products))
+	for _, product := range products {
+		d := 0
+		for parent := product.ParentExternalId; parent != ""; d++ {
+			p, ok := byExternalId[parent]
+			if !ok {
+				return nil, fmt.Errorf("catalogsync: product %q: unknown parent %q", product.ExternalId, parent)
+			}
+			if p.Data.Type != uds.GoodsItemTypeCategory {
+				return nil, fmt.Errorf("catalogsync: product %q: parent %q is not a category", product.ExternalId, parent)
+			}
+			if d > len(products) {
+				return nil, fmt.Errorf("catalogsync: product %q: parent cycle", product.ExternalId)
+			}
+			parent = p.ParentExternalId
+		}
+		depth[product.ExternalId] = d
+	}
+
+	ordered := slices.Clone(products)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return depth[ordered[i].ExternalId] < depth[ordered[j].ExternalId]
+	})
+	return ordered, nil
+}
+
+// syncRun
+// Состояние одной синхронизации: каталог UDS с учетом уже выполненных изменений.
+type syncRun struct {
+	syncer       *Syncer
+	report       *Report
+	byExternalId map[string]*uds.GoodsNode // Элементы UDS с externalId (первый из повторяющихся).
+	nodes        map[int64]*uds.GoodsNode  // Все элементы UDS.
+	duplicates   []*uds.GoodsNode          // Повторяющиеся по externalId элементы UDS.
+	failed       map[string]bool           // externalId элементов, которые не удалось создать.
+	nextDryRunID int64
+}
+
+func newSyncRun(s *Syncer, remote []uds.GoodsNode) *syncRun {
+	run := &syncRun{
+		syncer:       s,
+		report:       &Report{DryRun: s.dryRun},
+		byExternalId: map[string]*uds.GoodsNode{},
+		nodes:        map[int64]*uds.GoodsNode{},
+		failed:       map[string]bool{},
+	}
+
+	for i := range remote {
+		node := &remote[i]
+		run.nodes[node.Id] = node
+
+		if node.ExternalId == "" {
+			continue
+		}
+
+		if run.byExternalId[node.ExternalId] != nil {
+			run.duplicates = append(run.duplicates, node)
+			continue
+		}
+		run.byExternalId[node.ExternalId] = node
+	}
+
+	return run
+}
+
+// upsert
+// Создает или обновляет элемент.
+func (r *syncRun) upsert(ctx context.Context, product Product) {
+	change := Change{ExternalId: product.ExternalId, Name: product.Name, Type: product.Data.Type}
+
+	var parentID *int64
+	if product.ParentExternalId != "" {
+		parent := r.byExternalId[product.ParentExternalId]
+		if parent == nil {
+			change.Action = ActionCreate
+			if existing := r.byExternalId[product.ExternalId]; existing != nil {
+				change.Action, change.ID = ActionUpdate, existing.Id
+			}
+			r.skip(change, fmt.Sprintf("parent %q was not created", product.ParentExternalId))
+			r.failed[product.ExternalId] = true
+			return
+		}
+		parentID = &parent.Id
+	}
+
+	req := &uds.GoodsNodeRequest{
+		Name:       product.Name,
+		NodeId:     parentID,
+		ExternalId: product.ExternalId,
+		Hidden:     product.Hidden,
+		Data:       product.Data,
+	}
+
+	existing := r.byExternalId[product.ExternalId]
+	if existing == nil {
+		change.Action = ActionCreate
+		r.create(ctx, change, req)
+		return
+	}
+
+	// GoodsUpdate заменяет элемент целиком: поля, которые источник не задает, берутся из UDS.
+	req.Data = merge(existing.Data, product.Data)
+
+	change.Action = ActionUpdate
+	change.ID = existing.Id
+	change.Fields = diff(existing, req)
+	if len(change.Fields) == 0 {
+		r.report.Unchanged++
+		return
+	}
+
+	if r.syncer.dryRun {
+		r.apply(existing, req)
+		r.record(change, StatusPlanned, nil)
+		return
+	}
+
+	if _, _, err := r.syncer.goods.GoodsUpdateWithContext(ctx, existing.Id, req); err != nil {
+		r.record(change, StatusFailed, err)
+		return
+	}
+
+	r.apply(existing, req)
+	r.record(change, StatusApplied, nil)
+}
+
+func (r *syncRun) create(ctx context.Context, change Change, req *uds.GoodsNodeRequest) {
+	if r.report.LimitReached {
+		r.failed[change.ExternalId] = true
+		r.skip(change, "goods limit is reached")
+		return
+	}
+
+	if r.syncer.dryRun {
+		r.nextDryRunID--
+		node := &uds.GoodsNode{Id: r.nextDryRunID}
+		r.apply(node, req)
+		r.add(node)
+		change.ID = node.Id
+		r.record(change, StatusPlanned, nil)
+		return
+	}
+
+	node, _, err := r.syncer.goods.GoodsCreateWithContext(ctx, req)
+	if err != nil {
+		r.failed[change.ExternalId] = true
+		if errors.Is(err, uds.ErrGoodsLimitIsReached) {
+			r.report.LimitReached = true
+			change.Reason = "goods limit is reached"
+			r.record(change, StatusSkipped, err)
+			return
+		}
+		r.record(change, StatusFailed, err)
+		return
+	}
+
+	r.add(node)
+	change.ID = node.Id
+	r.record(change, StatusApplied, nil)
+}
+
+// deleteMissing
+// Удаляет элементы UDS с externalId, отсутствующие в источнике, и повторяющиеся элементы:
+// сначала товары, затем категории начиная с вложенных. Категория, в которой остаются
+// элементы, не удаляется.
+func (r *syncRun) deleteMissing(ctx context.Context, products []Product) {
+	desired := make(map[string]bool, len(products))
+	for _, product := range products {
+		desired[product.ExternalId] = true
+	}
+
+	var missing []*uds.GoodsNode
+	for externalId, node := range r.byExternalId {
+		if !desired[externalId] {
+			missing = append(missing, node)
+		}
+	}
+	missing = append(missing, r.duplicates...)
+
+	sort.SliceStable(missing, func(i, j int) bool {
+		ci, cj := missing[i].Data.Type == uds.GoodsItemTypeCategory, missing[j].Data.Type == uds.GoodsItemTypeCategory
+		if ci != cj {
+			return !ci
+		}
+		if di, dj := r.depth(missing[i]), r.depth(missing[j]); di != dj {
+			return di > dj
+		}
+		return missing[i].Id < missing[j].Id
+	})
+
+	for _, node := range missing {
+		change := Change{Action: ActionDelete, ExternalId: node.ExternalId, Name: node.Name, Type: node.Data.Type, ID: node.Id}
+
+		if node.Data.Type == uds.GoodsItemTypeCategory && r.hasChildren(node.Id) {
+			r.skip(change, "category still has children")
+			continue
+		}
+
+		if !r.syncer.dryRun {
+			if _, err := r.syncer.goods.GoodsDeleteWithContext(ctx, node.Id); err != nil && !errors.Is(err, uds.ErrNotFound) {
+				r.record(change, StatusFailed, err)
+				continue
+			}
+		}
+
+		r.remove(node)
+		if r.syncer.dryRun {
+			r.record(change, StatusPlanned, nil)
+		} else {
+			r.record(change, StatusApplied, nil)
+		}
+	}
+}
+
+func (r *syncRun) add(node *uds.GoodsNode) {
+	r.nodes[node.Id] = node
+	r.byExternalId[node.ExternalId] = node
+}
+
+func (r *syncRun) remove(node *uds.GoodsNode) {
+	delete(r.nodes, node.Id)
+	if r.byExternalId[node.ExternalId] == node {
+		delete(r.byExternalId, node.ExternalId)
+	}
+}
+
+func (r *syncRun) apply(node *uds.GoodsNode, req *uds.GoodsNodeRequest) {
+	node.Name = req.Name
+	node.NodeId = req.NodeId
+	node.ExternalId = req.ExternalId
+	node.Hidden = req.Hidden
+	node.Data = req.Data
+}
+
+func (r *syncRun) hasChildren(id int64) bool {
+	for _, node := range r.nodes {
+		if node.NodeId != nil && *node.NodeId == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *syncRun) depth(node *uds.GoodsNode) int {
+	d := 0
+	for node != nil && node.NodeId != nil && d <= len(r.nodes) {
+		node = r.nodes[*node.NodeId]
+		d++
+	}
+	return d
+}
+
+func (r *syncRun) skip(change Change, reason string) {
+	change.Reason = reason
+	r.record(change, StatusSkipped, nil)
+}
+
+func (r *syncRun) record(change Change, status Status, err error) {
+	change.Status = status
+	change.Err = err
+	r.report.Changes = append(r.report.Changes, change)
+
+	if r.syncer.hook != nil {
+		r.syncer.hook(change)
+	}
+}
+
+// diff
+// Поля элемента UDS, отличающиеся от запроса.
+func diff(node *uds.GoodsNode, req *uds.GoodsNodeRequest) []string {
+	var fields []string
+	if node.Name != req.Name {
+		fields = append(fields, "name")
+	}
+	if node.Hidden != req.Hidden {
+		fields = append(fields, "hidden")
+	}
+	if parentOf(node.NodeId) != parentOf(req.NodeId) {
+		fields = append(fields, "parent")
+	}
+
+	current, desired := reflect.ValueOf(normalize(node.Data)), reflect.ValueOf(normalize(req.Data))
+	for i := 0; i < current.NumField(); i++ {
+		if !reflect.DeepEqual(current.Field(i).Interface(), desired.Field(i).Interface()) {
+			name, _, _ := strings.Cut(current.Type().Field(i).Tag.Get("json"), ",")
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// merge
+// Данные из источника, в которых незаполненные поля взяты из элемента UDS того же типа.
+// Варианты сопоставляются по артикулу, а без артикула - по названию; набор вариантов,
+// их названия, артикулы и цены принадлежат источнику.
+func merge(current, desired uds.GoodsData) uds.GoodsData {
+	if current.Type != desired.Type {
+		return desired
+	}
+
+	merged := desired
+	if merged.Price == 0 {
+		merged.Price = current.Price
+	}
+	if merged.Sku == "" {
+		merged.Sku = current.Sku
+	}
+	if merged.Description == "" {
+		merged.Description = current.Description
+	}
+	if len(merged.Photos) == 0 {
+		merged.Photos = current.Photos
+	}
+	if merged.Measurement == "" {
+		merged.Measurement = current.Measurement
+	}
+	if merged.Increment == 0 {
+		merged.Increment = current.Increment
+	}
+	if merged.MinQuantity == 0 {
+		merged.MinQuantity = current.MinQuantity
+	}
+	if merged.Offer == nil {
+		merged.Offer = current.Offer
+	}
+	if merged.Inventory == nil {
+		merged.Inventory = current.Inventory
+	}
+
+	if len(desired.Variants) == 0 {
+		merged.Variants = current.Variants
+		return merged
+	}
+
+	merged.Variants = slices.Clone(desired.Variants)
+	for i := range merged.Variants {
+		variant := &merged.Variants[i]
+		j := slices.IndexFunc(current.Variants, func(v uds.GoodsVariant) bool {
+			if variant.Sku != "" {
+				return v.Sku == variant.Sku
+			}
+			return v.Name == variant.Name
+		})
+		if j < 0 {
+			continue
+		}
+
+		if variant.Offer == nil {
+			variant.Offer = current.Variants[j].Offer
+		}
+		if variant.Inventory == nil {
+			variant.Inventory = current.Variants[j].Inventory
+		}
+	}
+	return merged
+}
+
+// normalize
+// Данные без различий между nil и пустыми срезами.
+func normalize(data uds.GoodsData) uds.GoodsData {
+	if len(data.Photos) == 0 {
+		data.Photos = nil
+	}
+	if len(data.Variants) == 0 {
+		data.Variants = nil
+	}
+	return data
+}
+
+func parentOf(nodeId *int64) int64 {
+	if nodeId == nil {
+		return 0
+	}
+	return *nodeId
+}
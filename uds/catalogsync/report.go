@@ -0,0 +1,120 @@
+package catalogsync
+
+import (
+	"fmt"
+	"github.com/arcsub/go-uds/uds"
+	"strings"
+)
+
+// Action
+// Вид изменения каталога.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Status
+// Результат изменения.
+type Status string
+
+const (
+	StatusPlanned Status = "planned" // Изменение рассчитано в режиме WithDryRun.
+	StatusApplied Status = "applied" // Изменение выполнено.
+	StatusSkipped Status = "skipped" // Изменение пропущено, причина в Change.Reason.
+	StatusFailed  Status = "failed"  // API вернуло ошибку, она в Change.Err.
+)
+
+// Change
+// Изменение элемента каталога.
+type Change struct {
+	Action     Action            // Вид изменения.
+	Status     Status            // Результат.
+	ExternalId string            // Внешний идентификатор элемента.
+	Name       string            // Название элемента.
+	Type       uds.GoodsItemType // Тип элемента.
+	ID         int64             // ID элемента в UDS (отрицательный для элементов, созданных в режиме WithDryRun).
+	Fields     []string          // Измененные поля для ActionUpdate: name, hidden, parent, data.
+	Reason     string            // Причина пропуска.
+	Err        error             // Ошибка API.
+}
+
+func (c Change) String() string {
+	s := fmt.Sprintf("%s %s %s %q (%s)", c.Status, c.Action, c.Type, c.Name, c.ExternalId)
+	if len(c.Fields) > 0 {
+		s += " fields: " + strings.Join(c.Fields, ", ")
+	}
+	if c.Reason != "" {
+		s += ": " + c.Reason
+	}
+	if c.Err != nil {
+		s += ": " + c.Err.Error()
+	}
+	return s
+}
+
+// Report
+// Отчет о синхронизации.
+type Report struct {
+	DryRun       bool     // Синхронизация выполнена в режиме WithDryRun.
+	Changes      []Change // Изменения в порядке выполнения.
+	Unchanged    int      // Количество элементов, не требующих изменений.
+	LimitReached bool     // API вернуло ErrGoodsLimitIsReached, часть элементов не создана.
+}
+
+// Count
+// Количество изменений вида action с результатом status.
+func (r *Report) Count(action Action, status Status) int {
+	n := 0
+	for _, change := range r.Changes {
+		if change.Action == action && change.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed
+// Изменения, которые не удалось выполнить или пришлось пропустить.
+func (r *Report) Failed() []Change {
+	var failed []Change
+	for _, change := range r.Changes {
+		if change.Status == StatusFailed || change.Status == StatusSkipped {
+			failed = append(failed, change)
+		}
+	}
+	return failed
+}
+
+func (r *Report) String() string {
+	done := StatusApplied
+	if r.DryRun {
+		done = StatusPlanned
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d created, %d updated, %d deleted, %d unchanged, %d failed, %d skipped",
+		done, r.Count(ActionCreate, done), r.Count(ActionUpdate, done), r.Count(ActionDelete, done), r.Unchanged,
+		r.count(StatusFailed), r.count(StatusSkipped))
+	if r.LimitReached {
+		b.WriteString(", goods limit is reached")
+	}
+
+	for _, change := range r.Changes {
+		b.WriteString("\n")
+		b.WriteString(change.String())
+	}
+	return b.String()
+}
+
+func (r *Report) count(status Status) int {
+	n := 0
+	for _, change := range r.Changes {
+		if change.Status == status {
+			n++
+		}
+	}
+	return n
+}
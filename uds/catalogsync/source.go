@@ -0,0 +1,224 @@
+package catalogsync
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/arcsub/go-uds/uds"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Product
+// Желаемый элемент каталога: товар, вариативный товар или категория.
+type Product struct {
+	ExternalId       string        `json:"externalId"`                 // Внешний идентификатор, по которому элемент сопоставляется с каталогом UDS.
+	ParentExternalId string        `json:"parentExternalId,omitempty"` // Внешний идентификатор родительской категории. Пустая строка - корень каталога.
+	Name             string        `json:"name"`                       // Название.
+	Hidden           bool          `json:"hidden,omitempty"`           // Скрыть элемент в приложении.
+	Data             uds.GoodsData `json:"data"`                       // Данные товара или категории, Data.Type обязателен.
+}
+
+// ProductSource
+// Источник желаемого дерева каталога.
+type ProductSource interface {
+	Products(ctx context.Context) ([]Product, error)
+}
+
+// ProductSourceFunc
+// Функция, реализующая ProductSource.
+type ProductSourceFunc func(ctx context.Context) ([]Product, error)
+
+func (f ProductSourceFunc) Products(ctx context.Context) ([]Product, error) {
+	return f(ctx)
+}
+
+// JSONFile
+// Источник из JSON файла с массивом Product.
+type JSONFile string
+
+func (f JSONFile) Products(_ context.Context) ([]Product, error) {
+	file, err := os.Open(string(f))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadJSON(file)
+}
+
+// ReadJSON
+// Читает массив Product в формате JSON.
+func ReadJSON(r io.Reader) ([]Product, error) {
+	var products []Product
+	if err := json.NewDecoder(r).Decode(&products); err != nil {
+		return nil, fmt.Errorf("catalogsync: decode json: %w", err)
+	}
+	return products, nil
+}
+
+// CSVFile
+// Источник из CSV файла в формате ReadCSV.
+type CSVFile string
+
+func (f CSVFile) Products(_ context.Context) ([]Product, error) {
+	file, err := os.Open(string(f))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadCSV(file)
+}
+
+// ReadCSV
+// Читает каталог в формате CSV с заголовком. Обязательные колонки: externalId, type (ITEM, VARYING_ITEM или CATEGORY), name.
+// Необязательные: parentExternalId, hidden, price, sku, description, measurement,
+// photos (ссылки через |), variantName, variantPrice, variantSku. Остальные колонки игнорируются.
+// Варианты товара VARYING_ITEM задаются несколькими строками с одним externalId,
+// по одной на вариант; остальные поля берутся из первой строки.
+func ReadCSV(r io.Reader) ([]Product, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("catalogsync: read csv header: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+
+	for _, required := range []string{"externalId", "type", "name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("catalogsync: csv column %q is required", required)
+		}
+	}
+
+	var products []Product
+	index := map[string]int{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return products, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("catalogsync: read csv: %w", err)
+		}
+
+		row := csvRow{columns: columns, record: record, line: line}
+		product, variant, err := row.product()
+		if err != nil {
+			return nil, err
+		}
+
+		if i, ok := index[product.ExternalId]; ok {
+			if variant == nil || products[i].Data.Type != uds.GoodsItemTypeVaryingItem {
+				return nil, fmt.Errorf("catalogsync: csv line %d: duplicate externalId %q", line, product.ExternalId)
+			}
+			products[i].Data.Variants = append(products[i].Data.Variants, *variant)
+			continue
+		}
+
+		if variant != nil {
+			product.Data.Variants = []uds.GoodsVariant{*variant}
+		}
+
+		index[product.ExternalId] = len(products)
+		products = append(products, product)
+	}
+}
+
+// csvRow
+// Строка CSV с доступом к значениям по названию колонки.
+type csvRow struct {
+	columns map[string]int
+	record  []string
+	line    int
+}
+
+func (r csvRow) get(column string) string {
+	i, ok := r.columns[column]
+	if !ok || i >= len(r.record) {
+		return ""
+	}
+	return strings.TrimSpace(r.record[i])
+}
+
+func (r csvRow) float(column string) (float64, error) {
+	value := r.get(column)
+	if value == "" {
+		return 0, nil
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("catalogsync: csv line %d: column %s: %w", r.line, column, err)
+	}
+	return f, nil
+}
+
+// product
+// Элемент каталога и вариант товара из строки (nil, если variantName не заполнен).
+func (r csvRow) product() (Product, *uds.GoodsVariant, error) {
+	product := Product{
+		ExternalId:       r.get("externalId"),
+		ParentExternalId: r.get("parentExternalId"),
+		Name:             r.get("name"),
+		Data: uds.GoodsData{
+			Type:        uds.GoodsItemType(strings.ToUpper(r.get("type"))),
+			Sku:         r.get("sku"),
+			Description: r.get("description"),
+			Measurement: uds.GoodsMeasurement(strings.ToUpper(r.get("measurement"))),
+		},
+	}
+
+	switch product.Data.Type {
+	case uds.GoodsItemTypeItem, uds.GoodsItemTypeVaryingItem, uds.GoodsItemTypeCategory:
+	default:
+		return Product{}, nil, fmt.Errorf("catalogsync: csv line %d: column type: unknown type %q", r.line, r.get("type"))
+	}
+
+	if hidden := r.get("hidden"); hidden != "" {
+		value, err := strconv.ParseBool(hidden)
+		if err != nil {
+			return Product{}, nil, fmt.Errorf("catalogsync: csv line %d: column hidden: %w", r.line, err)
+		}
+		product.Hidden = value
+	}
+
+	price, err := r.float("price")
+	if err != nil {
+		return Product{}, nil, err
+	}
+	product.Data.Price = price
+
+	if photos := r.get("photos"); photos != "" {
+		for _, photo := range strings.Split(photos, "|") {
+			if photo = strings.TrimSpace(photo); photo != "" {
+				product.Data.Photos = append(product.Data.Photos, photo)
+			}
+		}
+	}
+
+	variantName := r.get("variantName")
+	if variantName == "" {
+		return product, nil, nil
+	}
+
+	variantPrice, err := r.float("variantPrice")
+	if err != nil {
+		return Product{}, nil, err
+	}
+
+	return product, &uds.GoodsVariant{Name: variantName, Sku: r.get("variantSku"), Price: variantPrice}, nil
+}
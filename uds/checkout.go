@@ -0,0 +1,346 @@
+package uds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+)
+
+// CheckoutState
+// Состояние проведения оплаты.
+type CheckoutState string
+
+const (
+	CheckoutStateNew           CheckoutState = "NEW"            // Клиент еще не найден.
+	CheckoutStateCustomerFound CheckoutState = "CUSTOMER_FOUND" // Клиент найден по коду (CustomerFindByCode).
+	CheckoutStateCalculated    CheckoutState = "CALCULATED"     // Суммы рассчитаны (OperationCalc).
+	CheckoutStateCreated       CheckoutState = "CREATED"        // Операция проведена (OperationCreate).
+	CheckoutStateConfirmed     CheckoutState = "CONFIRMED"      // Операция подтверждена (OperationGetByID).
+	CheckoutStateCodeExpired   CheckoutState = "CODE_EXPIRED"   // Код на оплату недействителен, нужен новый код (Checkout.Restart).
+)
+
+const defaultCheckoutMaxRecalculations = 2
+
+var (
+	// ErrCheckoutCodeExpired
+	// Код на оплату не найден или истек его срок действия. Оплату можно продолжить с новым кодом через Checkout.Restart.
+	ErrCheckoutCodeExpired = errors.New("uds: checkout payment code expired")
+
+	// ErrCheckoutRecalculationLimit
+	// Баланс клиента менялся во время оплаты чаще, чем допускает WithCheckoutMaxRecalculations.
+	ErrCheckoutRecalculationLimit = errors.New("uds: checkout recalculation limit reached")
+
+	// ErrCheckoutAlreadyCreated
+	// Операция уже проведена, изменить код на оплату нельзя.
+	ErrCheckoutAlreadyCreated = errors.New("uds: checkout operation already created")
+)
+
+// Checkout
+// Проведение оплаты по коду клиента: CustomerFindByCode -> OperationCalc -> OperationCreate -> OperationGetByID.
+//
+// Все попытки OperationCreate используют один Nonce, поэтому Run можно безопасно вызывать повторно
+// после сетевой ошибки: операция не будет проведена дважды. Если между шагами баланс клиента изменился
+// и API вернуло ErrInsufficientFunds, суммы рассчитываются заново. Если код на оплату истек,
+// оплата переходит в CheckoutStateCodeExpired и ждет нового кода.
+//
+// Checkout не предназначен для одновременного использования из нескольких горутин.
+//
+//	checkout := uds.NewCheckout(client, code, uds.CalcOperationReceipt{Total: 1000},
+//		uds.WithCheckoutHook(func(c *uds.Checkout) { ui.Show(c.State(), c.Purchase()) }))
+//	operation, err := checkout.Run(ctx)
+//	if errors.Is(err, uds.ErrCheckoutCodeExpired) {
+//		checkout.Restart(ui.AskCode())
+//		operation, err = checkout.Run(ctx)
+//	}
+type Checkout struct {
+	api               API
+	code              string
+	receipt           CalcOperationReceipt
+	number            *string
+	cashier           *CashierExternal
+	tags              []int64
+	nonce             string
+	maxRecalculations int
+	hook              func(*Checkout)
+
+	state         CheckoutState
+	recalculation int
+	customer      *FindCustomerResponse
+	purchase      PurchaseDetail
+	created       *CreateOperationResponse
+	operation     *Operation
+	err           error
+}
+
+// CheckoutOption
+// Функциональная опция для NewCheckout
+type CheckoutOption func(*Checkout)
+
+// WithCheckoutReceiptNumber
+// Номер чека, передаваемый в OperationCreate.
+func WithCheckoutReceiptNumber(number string) CheckoutOption {
+	return func(c *Checkout) {
+		c.number = &number
+	}
+}
+
+// WithCheckoutCashier
+// Сотрудник, проводящий операцию.
+func WithCheckoutCashier(cashier CashierExternal) CheckoutOption {
+	return func(c *Checkout) {
+		c.cashier = &cashier
+	}
+}
+
+// WithCheckoutTags
+// ID тегов компании, назначаемых клиенту при проведении операции.
+func WithCheckoutTags(tags ...int64) CheckoutOption {
+	return func(c *Checkout) {
+		c.tags = tags
+	}
+}
+
+// WithCheckoutNonce
+// Nonce операции. Позволяет продолжить оплату после перезапуска кассы, сохранив Checkout.Nonce.
+// По умолчанию генерируется новый UUID.
+func WithCheckoutNonce(nonce string) CheckoutOption {
+	return func(c *Checkout) {
+		c.nonce = nonce
+	}
+}
+
+// WithCheckoutMaxRecalculations
+// Сколько раз пересчитывать суммы при ErrInsufficientFunds. По умолчанию 2.
+func WithCheckoutMaxRecalculations(n int) CheckoutOption {
+	return func(c *Checkout) {
+		c.maxRecalculations = max(0, n)
+	}
+}
+
+// WithCheckoutHook
+// Функция, вызываемая при каждой смене состояния и при каждой ошибке шага.
+// Текущее состояние, суммы и ошибку можно получить из переданного Checkout.
+func WithCheckoutHook(hook func(*Checkout)) CheckoutOption {
+	return func(c *Checkout) {
+		c.hook = hook
+	}
+}
+
+// NewCheckout
+// Создает оплату по коду клиента code на сумму receipt.
+// Если receipt.Points равно nil, списывается максимально доступное количество баллов.
+func NewCheckout(api API, code string, receipt CalcOperationReceipt, opts ...CheckoutOption) *Checkout {
+	c := &Checkout{
+		api:               api,
+		code:              code,
+		receipt:           receipt,
+		maxRecalculations: defaultCheckoutMaxRecalculations,
+		state:             CheckoutStateNew,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.nonce == "" {
+		c.nonce = uuid.New().String()
+	}
+
+	return c
+}
+
+// State Текущее состояние.
+func (c *Checkout) State() CheckoutState { return c.state }
+
+// Nonce Nonce операции, одинаковый для всех попыток OperationCreate.
+func (c *Checkout) Nonce() string { return c.nonce }
+
+// Customer Результат поиска клиента или nil, если клиент еще не найден.
+func (c *Checkout) Customer() *FindCustomerResponse { return c.customer }
+
+// Purchase Рассчитанные суммы операции.
+func (c *Checkout) Purchase() PurchaseDetail { return c.purchase }
+
+// Operation Подтвержденная операция или nil.
+func (c *Checkout) Operation() *Operation { return c.operation }
+
+// Err Ошибка последнего шага или nil.
+func (c *Checkout) Err() error { return c.err }
+
+// Restart
+// Продолжает оплату с новым кодом на оплату, начиная с поиска клиента.
+// Nonce сохраняется. Возвращает ErrCheckoutAlreadyCreated, если операция уже проведена.
+func (c *Checkout) Restart(code string) error {
+	if c.created != nil {
+		return ErrCheckoutAlreadyCreated
+	}
+
+	c.code = code
+	c.recalculation = 0
+	c.customer = nil
+	c.purchase = PurchaseDetail{}
+	c.err = nil
+	c.setState(CheckoutStateNew)
+	return nil
+}
+
+// Run
+// Выполняет оставшиеся шаги до подтверждения операции.
+// При ошибке шага состояние не меняется, и повторный вызов Run повторит этот шаг.
+func (c *Checkout) Run(ctx context.Context) (*Operation, error) {
+	for {
+		var err error
+
+		switch c.state {
+		case CheckoutStateNew:
+			err = c.find(ctx)
+		case CheckoutStateCustomerFound:
+			err = c.calc(ctx)
+		case CheckoutStateCalculated:
+			err = c.create(ctx)
+		case CheckoutStateCreated:
+			err = c.confirm(ctx)
+		case CheckoutStateConfirmed:
+			return c.operation, nil
+		case CheckoutStateCodeExpired:
+			return nil, c.err
+		}
+
+		if err != nil {
+			return nil, c.fail(err)
+		}
+	}
+}
+
+func (c *Checkout) find(ctx context.Context) error {
+	params := &FindCustomerParams{
+		Total:             c.receipt.Total,
+		SkipLoyaltyTotal:  valueOrZero(c.receipt.SkipLoyaltyTotal),
+		UnredeemableTotal: valueOrZero(c.receipt.UnredeemableTotal),
+	}
+
+	customer, _, err := c.api.CustomerFindByCodeWithContext(ctx, c.code, params)
+	if err != nil {
+		return err
+	}
+
+	c.customer = customer
+	c.err = nil
+	c.setState(CheckoutStateCustomerFound)
+	return nil
+}
+
+func (c *Checkout) calc(ctx context.Context) error {
+	receipt := c.receipt
+	if receipt.Points != nil {
+		// Клиент не может списать больше, чем доступно с учетом текущего баланса.
+		points, err := PointsFromFloat(*receipt.Points)
+		if err != nil {
+			return fmt.Errorf("uds: receipt.points: %w", err)
+		}
+		maxPoints, err := PointsFromFloat(c.customer.Purchase.MaxPoints)
+		if err != nil {
+			return fmt.Errorf("uds: maxPoints: %w", err)
+		}
+		spend := points.Min(maxPoints).Float64()
+		receipt.Points = &spend
+	}
+
+	calc, _, err := c.api.OperationCalcWithContext(ctx, &CalcOperationRequest{Code: &c.code, Receipt: receipt})
+	if err != nil {
+		return err
+	}
+
+	c.purchase = calc.Purchase
+	c.err = nil
+	c.setState(CheckoutStateCalculated)
+	return nil
+}
+
+func (c *Checkout) create(ctx context.Context) error {
+	req := &CreateOperationRequest{
+		Code:    &c.code,
+		Nonce:   c.nonce,
+		Cashier: c.cashier,
+		Receipt: Receipt{
+			Total:             c.purchase.Total,
+			Cash:              c.purchase.Cash,
+			Points:            c.purchase.Points,
+			Number:            c.number,
+			SkipLoyaltyTotal:  c.receipt.SkipLoyaltyTotal,
+			UnredeemableTotal: c.receipt.UnredeemableTotal,
+		},
+		Tags: c.tags,
+	}
+
+	created, _, err := c.api.OperationCreateWithContext(ctx, req)
+	if errors.Is(err, ErrInsufficientFunds) {
+		return c.recalculate(err)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.created = created
+	c.err = nil
+	c.setState(CheckoutStateCreated)
+	return nil
+}
+
+// recalculate
+// Баланс клиента уменьшился после расчета: повторить поиск клиента и расчет с тем же Nonce.
+func (c *Checkout) recalculate(err error) error {
+	if c.recalculation >= c.maxRecalculations {
+		return fmt.Errorf("%w: %w", ErrCheckoutRecalculationLimit, err)
+	}
+
+	c.recalculation++
+	c.err = err
+	c.setState(CheckoutStateNew)
+	return nil
+}
+
+func (c *Checkout) confirm(ctx context.Context) error {
+	operation, _, err := c.api.OperationGetByIDWithContext(ctx, c.created.Id)
+	if err != nil {
+		return err
+	}
+
+	c.operation = operation
+	c.err = nil
+	c.setState(CheckoutStateConfirmed)
+	return nil
+}
+
+// fail
+// Запоминает ошибку шага. Ненайденный код до проведения операции означает, что код истек.
+func (c *Checkout) fail(err error) error {
+	if c.created == nil && errors.Is(err, ErrNotFound) {
+		c.err = fmt.Errorf("%w: %w", ErrCheckoutCodeExpired, err)
+		c.setState(CheckoutStateCodeExpired)
+		return c.err
+	}
+
+	c.err = err
+	c.notify()
+	return err
+}
+
+func (c *Checkout) setState(state CheckoutState) {
+	c.state = state
+	c.notify()
+}
+
+func (c *Checkout) notify() {
+	if c.hook != nil {
+		c.hook(c)
+	}
+}
+
+func valueOrZero(value *float64) float64 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
@@ -0,0 +1,84 @@
+package uds
+
+import (
+	"context"
+	"errors"
+	"github.com/arcsub/go-uds/uds/internal/fileutil"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+)
+
+// CheckpointStore
+// Хранилище курсора списка операций, с которого OperationStream продолжает чтение после перезапуска.
+type CheckpointStore interface {
+	// Load Возвращает сохраненный курсор или пустую строку, если курсор еще не сохранялся.
+	Load(ctx context.Context) (string, error)
+	// Save Сохраняет курсор.
+	Save(ctx context.Context, cursor string) error
+}
+
+// MemoryCheckpointStore
+// Хранилище курсора в памяти процесса.
+type MemoryCheckpointStore struct {
+	mu     sync.Mutex
+	cursor string
+}
+
+// NewMemoryCheckpointStore
+// Создает хранилище в памяти с начальным курсором cursor.
+func NewMemoryCheckpointStore(cursor string) *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{cursor: cursor}
+}
+
+func (s *MemoryCheckpointStore) Load(_ context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cursor, nil
+}
+
+func (s *MemoryCheckpointStore) Save(_ context.Context, cursor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cursor = cursor
+	return nil
+}
+
+// FileCheckpointStore
+// Хранилище курсора в файле. Файл перезаписывается атомарно через временный файл,
+// поэтому после сбоя в нем остается либо старый, либо новый курсор.
+type FileCheckpointStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileCheckpointStore
+// Создает хранилище курсора в файле path. Отсутствующий файл означает пустой курсор.
+func NewFileCheckpointStore(path string) *FileCheckpointStore {
+	return &FileCheckpointStore{path: path}
+}
+
+func (s *FileCheckpointStore) Load(_ context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+func (s *FileCheckpointStore) Save(_ context.Context, cursor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return fileutil.WriteAtomic(s.path, []byte(cursor+"\n"))
+}
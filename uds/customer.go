@@ -1,6 +1,7 @@
 package uds
 
 import (
+	"context"
 	"github.com/go-resty/resty/v2"
 	"strconv"
 	"time"
@@ -69,25 +70,30 @@ type CustomerShortInfo struct {
 // Получить список клиентов
 // https://docs.uds.app/#tag/Customers/paths/~1customers/get
 func (u *Client) CustomerGetList(maxValue int, offset int) (*List[Customer], *resty.Response, error) {
+	return u.CustomerGetListWithContext(context.Background(), maxValue, offset)
+}
+
+// CustomerGetListWithContext
+// Получить список клиентов с учетом контекста ctx
+// https://docs.uds.app/#tag/Customers/paths/~1customers/get
+func (u *Client) CustomerGetListWithContext(ctx context.Context, maxValue int, offset int) (*List[Customer], *resty.Response, error) {
 	customers := new(List[Customer])
 
-	req := u.client.R()
+	req := u.newRequest(ctx)
 
 	if maxValue > 0 {
-		maxValue = max(1, min(50, maxValue)) // от 1 до 50
+		maxValue = max(1, min(CustomerListMaxPageSize, maxValue)) // от 1 до 50
 		maxString := strconv.Itoa(maxValue)
 		req.SetQueryParam("max", maxString)
 	}
 
 	if offset > 0 {
-		offset = max(1, min(10000, offset)) // от 1 до 10000
+		offset = max(1, min(CustomerListMaxOffset, offset)) // от 1 до 10000
 		offsetString := strconv.Itoa(offset)
 		req.SetQueryParam("offset", offsetString)
 	}
 
-	resp, err := u.client.R().
-		SetResult(customers).
-		Get("customers")
+	resp, err := u.execute("CustomerGetList", req.SetResult(customers), resty.MethodGet, "customers")
 
 	if err != nil {
 		return nil, resp, err
@@ -108,9 +114,8 @@ func float64ToString(value float64) string {
 }
 
 // findCustomerProcess осуществляет запрос на поиск клиента по нужному ключу
-func findCustomerProcess(req *resty.Request, params *FindCustomerParams) (*FindCustomerResponse, *resty.Response, error) {
+func (u *Client) findCustomerProcess(operation string, req *resty.Request, params *FindCustomerParams) (*FindCustomerResponse, *resty.Response, error) {
 	customer := new(FindCustomerResponse)
-	apiErr := new(ApiError)
 
 	if params != nil {
 		if params.ExchangeCode {
@@ -127,25 +132,18 @@ func findCustomerProcess(req *resty.Request, params *FindCustomerParams) (*FindC
 			req.SetQueryParam("skipLoyaltyTotal", strVal)
 		}
 
-		if params.SkipLoyaltyTotal > 0 {
-			strVal := float64ToString(params.SkipLoyaltyTotal)
-			req.SetQueryParam("skipLoyaltyTotal", strVal)
+		if params.UnredeemableTotal > 0 {
+			strVal := float64ToString(params.UnredeemableTotal)
+			req.SetQueryParam("unredeemableTotal", strVal)
 		}
 	}
 
-	resp, err := req.
-		SetResult(customer).
-		SetError(apiErr).
-		Get("customers/find")
+	resp, err := u.execute(operation, req.SetResult(customer), resty.MethodGet, "customers/find")
 
 	if err != nil {
 		return nil, resp, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp, apiErr
-	}
-
 	return customer, resp, nil
 }
 
@@ -154,8 +152,16 @@ func findCustomerProcess(req *resty.Request, params *FindCustomerParams) (*FindC
 // params может быть nil
 // https://docs.uds.app/#tag/Customers/paths/~1customers~1find/get
 func (u *Client) CustomerFindByCode(code string, params *FindCustomerParams) (*FindCustomerResponse, *resty.Response, error) {
-	req := u.client.R().SetQueryParam("code", code)
-	return findCustomerProcess(req, params)
+	return u.CustomerFindByCodeWithContext(context.Background(), code, params)
+}
+
+// CustomerFindByCodeWithContext
+// Поиск клиента по коду из приложения с учетом контекста ctx
+// params может быть nil
+// https://docs.uds.app/#tag/Customers/paths/~1customers~1find/get
+func (u *Client) CustomerFindByCodeWithContext(ctx context.Context, code string, params *FindCustomerParams) (*FindCustomerResponse, *resty.Response, error) {
+	req := u.newRequest(ctx).SetQueryParam("code", code)
+	return u.findCustomerProcess("CustomerFindByCode", req, params)
 }
 
 // CustomerFindByPhone
@@ -163,8 +169,16 @@ func (u *Client) CustomerFindByCode(code string, params *FindCustomerParams) (*F
 // params может быть nil
 // https://docs.uds.app/#tag/Customers/paths/~1customers~1find/get
 func (u *Client) CustomerFindByPhone(phone string, params *FindCustomerParams) (*FindCustomerResponse, *resty.Response, error) {
-	req := u.client.R().SetQueryParam("phone", phone)
-	return findCustomerProcess(req, params)
+	return u.CustomerFindByPhoneWithContext(context.Background(), phone, params)
+}
+
+// CustomerFindByPhoneWithContext
+// Поиск клиента по номеру телефона в формате +79998887766 с учетом контекста ctx
+// params может быть nil
+// https://docs.uds.app/#tag/Customers/paths/~1customers~1find/get
+func (u *Client) CustomerFindByPhoneWithContext(ctx context.Context, phone string, params *FindCustomerParams) (*FindCustomerResponse, *resty.Response, error) {
+	req := u.newRequest(ctx).SetQueryParam("phone", phone)
+	return u.findCustomerProcess("CustomerFindByPhone", req, params)
 }
 
 // CustomerFindByUID
@@ -172,33 +186,43 @@ func (u *Client) CustomerFindByPhone(phone string, params *FindCustomerParams) (
 // params может быть nil
 // https://docs.uds.app/#tag/Customers/paths/~1customers~1find/get
 func (u *Client) CustomerFindByUID(uid string, params *FindCustomerParams) (*FindCustomerResponse, *resty.Response, error) {
-	req := u.client.R().SetQueryParam("uid", uid)
-	return findCustomerProcess(req, params)
+	return u.CustomerFindByUIDWithContext(context.Background(), uid, params)
+}
+
+// CustomerFindByUIDWithContext
+// Поиск клиента по uid с учетом контекста ctx
+// params может быть nil
+// https://docs.uds.app/#tag/Customers/paths/~1customers~1find/get
+func (u *Client) CustomerFindByUIDWithContext(ctx context.Context, uid string, params *FindCustomerParams) (*FindCustomerResponse, *resty.Response, error) {
+	req := u.newRequest(ctx).SetQueryParam("uid", uid)
+	return u.findCustomerProcess("CustomerFindByUID", req, params)
 }
 
 // CustomerGetByID
 // Получение информации о клиенте по ID
 // https://docs.uds.app/#tag/Customers/paths/~1customers~1{id}/get
 func (u *Client) CustomerGetByID(id int64) (*CustomerDetail, *resty.Response, error) {
+	return u.CustomerGetByIDWithContext(context.Background(), id)
+}
+
+// CustomerGetByIDWithContext
+// Получение информации о клиенте по ID с учетом контекста ctx
+// https://docs.uds.app/#tag/Customers/paths/~1customers~1{id}/get
+func (u *Client) CustomerGetByIDWithContext(ctx context.Context, id int64) (*CustomerDetail, *resty.Response, error) {
 	customer := new(CustomerDetail)
-	apiErr := new(ApiError)
 
 	idString := strconv.FormatInt(id, 10)
 
-	resp, err := u.client.R().
+	req := u.newRequest(ctx).
 		SetPathParam("id", idString).
-		SetResult(customer).
-		SetError(apiErr).
-		Get("customers/{id}")
+		SetResult(customer)
+
+	resp, err := u.execute("CustomerGetByID", req, resty.MethodGet, "customers/{id}")
 
 	if err != nil {
 		return nil, resp, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp, apiErr
-	}
-
 	return customer, resp, nil
 }
 
@@ -220,25 +244,27 @@ type TagModel struct {
 // Получение списка тегов клиента
 // https://docs.uds.app/#tag/Customers/paths/~1customers~1{id}~1tags/get
 func (u *Client) CustomerGetTags(id int64) (*CustomerTagList, *resty.Response, error) {
+	return u.CustomerGetTagsWithContext(context.Background(), id)
+}
+
+// CustomerGetTagsWithContext
+// Получение списка тегов клиента с учетом контекста ctx
+// https://docs.uds.app/#tag/Customers/paths/~1customers~1{id}~1tags/get
+func (u *Client) CustomerGetTagsWithContext(ctx context.Context, id int64) (*CustomerTagList, *resty.Response, error) {
 	tags := new(CustomerTagList)
-	apiErr := new(ApiError)
 
 	idString := strconv.FormatInt(id, 10)
 
-	resp, err := u.client.R().
+	req := u.newRequest(ctx).
 		SetPathParam("id", idString).
-		SetResult(tags).
-		SetError(apiErr).
-		Get("customers/{id}/tags")
+		SetResult(tags)
+
+	resp, err := u.execute("CustomerGetTags", req, resty.MethodGet, "customers/{id}/tags")
 
 	if err != nil {
 		return nil, resp, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp, apiErr
-	}
-
 	return tags, resp, nil
 }
 
@@ -252,25 +278,27 @@ type SetCustomerTagsRequest struct {
 // Установка тегов клиенту
 // https://docs.uds.app/#tag/Customers/paths/~1customers~1{id}~1tags/get
 func (u *Client) CustomerSetTags(id int64, tagsReq SetCustomerTagsRequest) (*List[TagModel], *resty.Response, error) {
+	return u.CustomerSetTagsWithContext(context.Background(), id, tagsReq)
+}
+
+// CustomerSetTagsWithContext
+// Установка тегов клиенту с учетом контекста ctx
+// https://docs.uds.app/#tag/Customers/paths/~1customers~1{id}~1tags/get
+func (u *Client) CustomerSetTagsWithContext(ctx context.Context, id int64, tagsReq SetCustomerTagsRequest) (*List[TagModel], *resty.Response, error) {
 	tags := new(List[TagModel])
-	apiErr := new(ApiError)
 
 	idString := strconv.FormatInt(id, 10)
 
-	resp, err := u.client.R().
+	req := u.newRequest(ctx).
 		SetPathParam("id", idString).
 		SetBody(tagsReq).
-		SetResult(tags).
-		SetError(apiErr).
-		Post("customers/{id}/tags")
+		SetResult(tags)
+
+	resp, err := u.execute("CustomerSetTags", req, resty.MethodPost, "customers/{id}/tags")
 
 	if err != nil {
 		return nil, resp, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp, apiErr
-	}
-
 	return tags, resp, nil
 }
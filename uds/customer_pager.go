@@ -0,0 +1,106 @@
+package uds
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	CustomerListMaxPageSize = 50    // Максимальное значение параметра max в CustomerGetList.
+	CustomerListMaxOffset   = 10000 // Максимальное значение параметра offset в CustomerGetList.
+)
+
+// ErrCustomerOffsetLimit
+// Обход списка клиентов остановлен, так как следующая страница начинается
+// дальше максимального смещения CustomerListMaxOffset.
+var ErrCustomerOffsetLimit = errors.New("uds: customer list offset limit reached")
+
+// CustomerPager
+// Постраничный обход всех клиентов компании страницами по CustomerListMaxPageSize.
+//
+//	pager := uds.NewCustomerPager(client, savedOffset)
+//	for pager.Next(ctx) {
+//		customer := pager.Customer()
+//		...
+//	}
+//	if err := pager.Err(); err != nil { ... }
+//	savedOffset = pager.Offset()
+type CustomerPager struct {
+	customers CustomersAPI
+	offset    int // Смещение следующего непрочитанного клиента.
+	page      []Customer
+	current   Customer
+	done      bool
+	err       error
+}
+
+// NewCustomerPager
+// Создает обход списка клиентов, начиная со смещения offset.
+// Для продолжения прерванного обхода передайте значение CustomerPager.Offset.
+func NewCustomerPager(customers CustomersAPI, offset int) *CustomerPager {
+	return &CustomerPager{
+		customers: customers,
+		offset:    max(0, offset),
+	}
+}
+
+// Next
+// Переходит к следующему клиенту, при необходимости загружая следующую страницу.
+// Возвращает false, когда клиенты закончились, обход прерван отменой ctx или произошла ошибка.
+func (p *CustomerPager) Next(ctx context.Context) bool {
+	if p.err != nil {
+		return false
+	}
+
+	if err := ctx.Err(); err != nil {
+		p.err = err
+		return false
+	}
+
+	if len(p.page) == 0 {
+		if p.done {
+			return false
+		}
+
+		if p.offset > CustomerListMaxOffset {
+			p.err = ErrCustomerOffsetLimit
+			return false
+		}
+
+		list, _, err := p.customers.CustomerGetListWithContext(ctx, CustomerListMaxPageSize, p.offset)
+		if err != nil {
+			p.err = err
+			return false
+		}
+
+		p.page = list.Rows
+		p.done = len(list.Rows) < CustomerListMaxPageSize
+
+		if len(p.page) == 0 {
+			return false
+		}
+	}
+
+	p.current = p.page[0]
+	p.page = p.page[1:]
+	p.offset++
+	return true
+}
+
+// Customer
+// Текущий клиент после успешного вызова Next.
+func (p *CustomerPager) Customer() Customer {
+	return p.current
+}
+
+// Err
+// Ошибка, остановившая обход, или nil, если клиенты просто закончились.
+func (p *CustomerPager) Err() error {
+	return p.err
+}
+
+// Offset
+// Смещение следующего непрочитанного клиента. Используется для продолжения обхода после перезапуска.
+func (p *CustomerPager) Offset() int {
+	return p.offset
+}
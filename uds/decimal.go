@@ -0,0 +1,370 @@
+package uds
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// minorUnits
+// Количество минимальных единиц (копеек, сотых долей балла) в одной единице.
+const minorUnits = 100
+
+// ErrDecimalOverflow
+// Значение не помещается в Money или Points.
+var ErrDecimalOverflow = errors.New("uds: decimal value out of range")
+
+// RoundingMode
+// Способ округления до сотых.
+type RoundingMode int
+
+const (
+	RoundHalfUp   RoundingMode = iota // Половина округляется от нуля (99.995 -> 100.00). Используется для денежных сумм по умолчанию.
+	RoundHalfEven                     // Половина округляется к четному (банковское округление).
+	RoundDown                         // Отбрасывание к нулю (99.999 -> 99.99). Используется для баллов всегда.
+	RoundUp                           // Округление от нуля (99.991 -> 100.00).
+)
+
+// Money
+// Денежная сумма с точностью до сотых без ошибок двоичного представления float64.
+// В JSON кодируется числом в том же виде, что и float64 (100, 99.5, 99.99).
+// Нулевое значение - ноль.
+type Money struct {
+	minor int64
+}
+
+// MoneyFromMinor
+// Сумма из количества минимальных единиц (копеек): MoneyFromMinor(9999) = 99.99.
+func MoneyFromMinor(minor int64) Money {
+	return Money{minor: minor}
+}
+
+// MoneyFromFloat
+// Сумма из float64, округленная до сотых способом mode.
+// Используется кратчайшее десятичное представление value, поэтому 0.1+0.2 дает 0.30.
+// Для NaN и бесконечностей возвращает ошибку, для значений вне диапазона - ErrDecimalOverflow.
+func MoneyFromFloat(value float64, mode RoundingMode) (Money, error) {
+	minor, err := floatToMinor(value, mode)
+	return Money{minor: minor}, err
+}
+
+// ParseMoney
+// Разбирает десятичное число (в том числе в экспоненциальной записи) и округляет до сотых способом mode.
+// Дроби вида "1/3" не принимаются.
+func ParseMoney(s string, mode RoundingMode) (Money, error) {
+	minor, err := parseMinor(s, mode)
+	return Money{minor: minor}, err
+}
+
+// Minor Количество минимальных единиц (копеек).
+func (m Money) Minor() int64 { return m.minor }
+
+// Float64 Сумма в виде float64 для полей API, использующих float64.
+func (m Money) Float64() float64 { return minorToFloat(m.minor) }
+
+// String Сумма в виде десятичного числа без лишних нулей.
+func (m Money) String() string { return formatMinor(m.minor) }
+
+// IsZero Сумма равна нулю.
+func (m Money) IsZero() bool { return m.minor == 0 }
+
+// Sign -1, 0 или 1 в зависимости от знака суммы.
+func (m Money) Sign() int { return sign(m.minor) }
+
+// Cmp -1, 0 или 1, если m меньше, равна или больше other.
+func (m Money) Cmp(other Money) int { return cmp.Compare(m.minor, other.minor) }
+
+// Add Сумма m + other.
+func (m Money) Add(other Money) Money { return Money{minor: m.minor + other.minor} }
+
+// Sub Разность m - other.
+func (m Money) Sub(other Money) Money { return Money{minor: m.minor - other.minor} }
+
+// Neg Сумма с противоположным знаком.
+func (m Money) Neg() Money { return Money{minor: -m.minor} }
+
+// Abs Модуль суммы.
+func (m Money) Abs() Money { return Money{minor: abs(m.minor)} }
+
+// Mul
+// Произведение суммы на factor (например, цены на количество), округленное способом mode.
+// Для NaN и бесконечностей возвращает ошибку, при переполнении - ErrDecimalOverflow.
+func (m Money) Mul(factor float64, mode RoundingMode) (Money, error) {
+	minor, err := mulMinor(m.minor, factor, 1, mode)
+	return Money{minor: minor}, err
+}
+
+// Percent
+// percent процентов от суммы, округленные способом mode. Ошибки те же, что у Mul.
+func (m Money) Percent(percent float64, mode RoundingMode) (Money, error) {
+	minor, err := mulMinor(m.minor, percent, 100, mode)
+	return Money{minor: minor}, err
+}
+
+// Points
+// Сумма в бонусных баллах (1 балл = 1 денежная единица).
+func (m Money) Points() Points {
+	return Points{minor: m.minor}
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON
+// Разбирает число JSON (или строку с числом), округляя до сотых способом RoundHalfUp.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	minor, ok, err := unmarshalMinor(data, RoundHalfUp)
+	if ok {
+		m.minor = minor
+	}
+	return err
+}
+
+// Points
+// Количество бонусных баллов с точностью до сотых.
+// Баллы всегда округляются вниз (к нулю), чтобы не списать больше, чем есть на балансе
+// (см. ErrInsufficientFunds). В JSON кодируется числом в том же виде, что и float64.
+// Нулевое значение - ноль.
+type Points struct {
+	minor int64
+}
+
+// PointsFromMinor
+// Баллы из количества сотых долей балла: PointsFromMinor(9999) = 99.99.
+func PointsFromMinor(minor int64) Points {
+	return Points{minor: minor}
+}
+
+// PointsFromFloat
+// Баллы из float64, округленные вниз до сотых.
+// Для NaN и бесконечностей возвращает ошибку, для значений вне диапазона - ErrDecimalOverflow.
+func PointsFromFloat(value float64) (Points, error) {
+	minor, err := floatToMinor(value, RoundDown)
+	return Points{minor: minor}, err
+}
+
+// ParsePoints
+// Разбирает десятичное число и округляет его вниз до сотых. Дроби вида "1/3" не принимаются.
+func ParsePoints(s string) (Points, error) {
+	minor, err := parseMinor(s, RoundDown)
+	return Points{minor: minor}, err
+}
+
+// Minor Количество сотых долей балла.
+func (p Points) Minor() int64 { return p.minor }
+
+// Float64 Баллы в виде float64 для полей API, использующих float64.
+func (p Points) Float64() float64 { return minorToFloat(p.minor) }
+
+// String Баллы в виде десятичного числа без лишних нулей.
+func (p Points) String() string { return formatMinor(p.minor) }
+
+// IsZero Баллы равны нулю.
+func (p Points) IsZero() bool { return p.minor == 0 }
+
+// Sign -1, 0 или 1 в зависимости от знака.
+func (p Points) Sign() int { return sign(p.minor) }
+
+// Cmp -1, 0 или 1, если p меньше, равно или больше other.
+func (p Points) Cmp(other Points) int { return cmp.Compare(p.minor, other.minor) }
+
+// Add Сумма p + other.
+func (p Points) Add(other Points) Points { return Points{minor: p.minor + other.minor} }
+
+// Sub Разность p - other.
+func (p Points) Sub(other Points) Points { return Points{minor: p.minor - other.minor} }
+
+// Neg Баллы с противоположным знаком.
+func (p Points) Neg() Points { return Points{minor: -p.minor} }
+
+// Abs Модуль количества баллов.
+func (p Points) Abs() Points { return Points{minor: abs(p.minor)} }
+
+// Min Меньшее из p и other.
+func (p Points) Min(other Points) Points {
+	if other.minor < p.minor {
+		return other
+	}
+	return p
+}
+
+// Mul
+// Произведение баллов на factor, округленное вниз.
+// Для NaN и бесконечностей возвращает ошибку, при переполнении - ErrDecimalOverflow.
+func (p Points) Mul(factor float64) (Points, error) {
+	minor, err := mulMinor(p.minor, factor, 1, RoundDown)
+	return Points{minor: minor}, err
+}
+
+// Percent
+// percent процентов от количества баллов, округленные вниз. Ошибки те же, что у Mul.
+func (p Points) Percent(percent float64) (Points, error) {
+	minor, err := mulMinor(p.minor, percent, 100, RoundDown)
+	return Points{minor: minor}, err
+}
+
+// Money
+// Баллы в денежных единицах (1 балл = 1 денежная единица).
+func (p Points) Money() Money {
+	return Money{minor: p.minor}
+}
+
+func (p Points) MarshalJSON() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalJSON
+// Разбирает число JSON (или строку с числом), округляя вниз до сотых.
+func (p *Points) UnmarshalJSON(data []byte) error {
+	minor, ok, err := unmarshalMinor(data, RoundDown)
+	if ok {
+		p.minor = minor
+	}
+	return err
+}
+
+// unmarshalMinor
+// Разбирает число JSON. ok равно false для null.
+func unmarshalMinor(data []byte, mode RoundingMode) (int64, bool, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return 0, false, nil
+	}
+
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' {
+		data = data[1 : len(data)-1]
+	}
+
+	minor, err := parseMinor(string(data), mode)
+	if err != nil {
+		return 0, false, err
+	}
+	return minor, true, nil
+}
+
+// parseMinor
+// Разбирает десятичное число в минимальные единицы с округлением mode.
+// big.Rat.SetString принимает и дроби ("1/3"), поэтому они отклоняются отдельно.
+func parseMinor(s string, mode RoundingMode) (int64, error) {
+	value, err := parseDecimal(s)
+	if err != nil {
+		return 0, err
+	}
+
+	return roundRat(value.Mul(value, big.NewRat(minorUnits, 1)), mode)
+}
+
+func parseDecimal(s string) (*big.Rat, error) {
+	if strings.Contains(s, "/") {
+		return nil, fmt.Errorf("uds: invalid decimal %q", s)
+	}
+
+	value, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("uds: invalid decimal %q", s)
+	}
+	return value, nil
+}
+
+func floatToRat(value float64) (*big.Rat, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, fmt.Errorf("uds: invalid decimal %v", value)
+	}
+
+	return parseDecimal(strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+func floatToMinor(value float64, mode RoundingMode) (int64, error) {
+	r, err := floatToRat(value)
+	if err != nil {
+		return 0, err
+	}
+
+	return roundRat(r.Mul(r, big.NewRat(minorUnits, 1)), mode)
+}
+
+// mulMinor
+// minor * factor / divisor с округлением mode.
+func mulMinor(minor int64, factor float64, divisor int64, mode RoundingMode) (int64, error) {
+	value, err := floatToRat(factor)
+	if err != nil {
+		return 0, err
+	}
+
+	value.Mul(value, new(big.Rat).SetInt64(minor))
+	value.Quo(value, big.NewRat(divisor, 1))
+
+	return roundRat(value, mode)
+}
+
+// roundRat
+// Округляет value до целого способом mode.
+func roundRat(value *big.Rat, mode RoundingMode) (int64, error) {
+	quo, rem := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+
+	if rem.Sign() != 0 {
+		step := big.NewInt(int64(value.Sign()))
+
+		switch mode {
+		case RoundDown:
+		case RoundUp:
+			quo.Add(quo, step)
+		default:
+			half := new(big.Int).Abs(rem)
+			half.Lsh(half, 1)
+			c := half.Cmp(value.Denom())
+			if c > 0 || c == 0 && (mode == RoundHalfUp || quo.Bit(0) == 1) {
+				quo.Add(quo, step)
+			}
+		}
+	}
+
+	if !quo.IsInt64() {
+		return 0, ErrDecimalOverflow
+	}
+	return quo.Int64(), nil
+}
+
+func formatMinor(minor int64) string {
+	prefix := ""
+	if minor < 0 {
+		prefix = "-"
+	}
+
+	u := uint64(minor)
+	if minor < 0 {
+		u = -u
+	}
+
+	s := prefix + strconv.FormatUint(u/minorUnits, 10)
+	switch fraction := u % minorUnits; {
+	case fraction == 0:
+		return s
+	case fraction%10 == 0:
+		return s + "." + strconv.FormatUint(fraction/10, 10)
+	default:
+		return fmt.Sprintf("%s.%02d", s, fraction)
+	}
+}
+
+func minorToFloat(minor int64) float64 {
+	value, _ := strconv.ParseFloat(formatMinor(minor), 64)
+	return value
+}
+
+func sign(value int64) int {
+	return cmp.Compare(value, 0)
+}
+
+func abs(value int64) int64 {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
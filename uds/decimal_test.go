@@ -0,0 +1,146 @@
+package uds_test
+
+import (
+	"errors"
+	"github.com/arcsub/go-uds/uds"
+	"math"
+	"testing"
+)
+
+func TestParseMoneyRoundingModes(t *testing.T) {
+	cases := []struct {
+		in   string
+		mode uds.RoundingMode
+		want string
+	}{
+		{"99.995", uds.RoundHalfUp, "100"},
+		{"-99.995", uds.RoundHalfUp, "-100"},
+		{"99.994", uds.RoundHalfUp, "99.99"},
+		{"0.125", uds.RoundHalfEven, "0.12"},
+		{"0.135", uds.RoundHalfEven, "0.14"},
+		{"-0.125", uds.RoundHalfEven, "-0.12"},
+		{"0.1251", uds.RoundHalfEven, "0.13"},
+		{"99.999", uds.RoundDown, "99.99"},
+		{"-99.999", uds.RoundDown, "-99.99"},
+		{"99.991", uds.RoundUp, "100"},
+		{"-99.991", uds.RoundUp, "-100"},
+		{"99.99", uds.RoundUp, "99.99"},
+		{"1.5e2", uds.RoundHalfUp, "150"},
+		{"1e-3", uds.RoundUp, "0.01"},
+	}
+
+	for _, c := range cases {
+		got, err := uds.ParseMoney(c.in, c.mode)
+		if err != nil {
+			t.Errorf("ParseMoney(%q, %d): %v", c.in, c.mode, err)
+			continue
+		}
+		if got.String() != c.want {
+			t.Errorf("ParseMoney(%q, %d) = %s, want %s", c.in, c.mode, got, c.want)
+		}
+	}
+}
+
+func TestParseMoneyRejectsFractions(t *testing.T) {
+	for _, in := range []string{"1/3", "100/1", "", "abc", "1.2.3"} {
+		if got, err := uds.ParseMoney(in, uds.RoundHalfUp); err == nil {
+			t.Errorf("ParseMoney(%q) = %s, want error", in, got)
+		}
+		if got, err := uds.ParsePoints(in); err == nil {
+			t.Errorf("ParsePoints(%q) = %s, want error", in, got)
+		}
+	}
+}
+
+func TestParsePointsRoundsDown(t *testing.T) {
+	for in, want := range map[string]string{"10.999": "10.99", "-10.999": "-10.99", "0.001": "0"} {
+		got, err := uds.ParsePoints(in)
+		if err != nil {
+			t.Fatalf("ParsePoints(%q): %v", in, err)
+		}
+		if got.String() != want {
+			t.Errorf("ParsePoints(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestDecimalFromFloat(t *testing.T) {
+	money, err := uds.MoneyFromFloat(0.1+0.2, uds.RoundHalfUp)
+	if err != nil || money.Minor() != 30 {
+		t.Errorf("MoneyFromFloat(0.1+0.2) = %s, %v, want 0.3", money, err)
+	}
+
+	points, err := uds.PointsFromFloat(99.999)
+	if err != nil || points.Minor() != 9999 {
+		t.Errorf("PointsFromFloat(99.999) = %s, %v, want 99.99", points, err)
+	}
+
+	for _, value := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := uds.MoneyFromFloat(value, uds.RoundHalfUp); err == nil {
+			t.Errorf("MoneyFromFloat(%v): want error", value)
+		}
+		if _, err := uds.PointsFromFloat(value); err == nil {
+			t.Errorf("PointsFromFloat(%v): want error", value)
+		}
+	}
+}
+
+func TestDecimalOverflow(t *testing.T) {
+	if _, err := uds.ParseMoney("1e30", uds.RoundHalfUp); !errors.Is(err, uds.ErrDecimalOverflow) {
+		t.Errorf("ParseMoney(1e30): err = %v, want ErrDecimalOverflow", err)
+	}
+	if _, err := uds.MoneyFromFloat(1e30, uds.RoundHalfUp); !errors.Is(err, uds.ErrDecimalOverflow) {
+		t.Errorf("MoneyFromFloat(1e30): err = %v, want ErrDecimalOverflow", err)
+	}
+	if _, err := uds.PointsFromFloat(-1e30); !errors.Is(err, uds.ErrDecimalOverflow) {
+		t.Errorf("PointsFromFloat(-1e30): err = %v, want ErrDecimalOverflow", err)
+	}
+
+	large := uds.MoneyFromMinor(math.MaxInt64 / 2)
+	if _, err := large.Mul(3, uds.RoundHalfUp); !errors.Is(err, uds.ErrDecimalOverflow) {
+		t.Errorf("Money.Mul overflow: err = %v, want ErrDecimalOverflow", err)
+	}
+	if _, err := large.Points().Percent(300); !errors.Is(err, uds.ErrDecimalOverflow) {
+		t.Errorf("Points.Percent overflow: err = %v, want ErrDecimalOverflow", err)
+	}
+	if _, err := large.Percent(math.NaN(), uds.RoundHalfUp); err == nil {
+		t.Error("Money.Percent(NaN): want error")
+	}
+}
+
+func TestDecimalMulAndPercent(t *testing.T) {
+	price := uds.MoneyFromMinor(3333)
+
+	got, err := price.Mul(3, uds.RoundHalfUp)
+	if err != nil || got.Minor() != 9999 {
+		t.Errorf("33.33 * 3 = %s, %v, want 99.99", got, err)
+	}
+
+	got, err = price.Percent(15, uds.RoundHalfUp)
+	if err != nil || got.Minor() != 500 {
+		t.Errorf("15%% of 33.33 = %s, %v, want 5", got, err)
+	}
+
+	got, err = price.Percent(15, uds.RoundUp)
+	if err != nil || got.Minor() != 500 {
+		t.Errorf("15%% of 33.33 rounded up = %s, %v, want 5", got, err)
+	}
+
+	points, err := uds.PointsFromMinor(3333).Percent(15)
+	if err != nil || points.Minor() != 499 {
+		t.Errorf("15%% of 33.33 points = %s, %v, want 4.99", points, err)
+	}
+}
+
+func TestPointsMin(t *testing.T) {
+	a, b := uds.PointsFromMinor(100), uds.PointsFromMinor(-50)
+	if got := a.Min(b); got != b {
+		t.Errorf("%s.Min(%s) = %s, want %s", a, b, got, b)
+	}
+	if got := b.Min(a); got != b {
+		t.Errorf("%s.Min(%s) = %s, want %s", b, a, got, b)
+	}
+	if got := a.Min(a); got != a {
+		t.Errorf("%s.Min(%s) = %s, want %s", a, a, got, a)
+	}
+}
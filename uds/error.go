@@ -1,11 +1,24 @@
 package uds
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"github.com/go-resty/resty/v2"
+	"net"
+	"net/http"
 )
 
+// ErrorCode
+// Код ошибки API. Константы ErrorCode можно использовать как цель errors.Is:
+//
+//	if errors.Is(err, uds.ErrInsufficientFunds) { ... }
 type ErrorCode string
 
+func (c ErrorCode) Error() string {
+	return string(c)
+}
+
 const (
 	// ErrNotFound
 	// Пользователь с данным кодом на оплату или идентификатором не найден.
@@ -84,10 +97,17 @@ const (
 	ErrParticipantIsBlocked ErrorCode = "participantIsBlocked"
 )
 
+// ApiError
+// Ошибка, возвращаемая API. Методы клиента возвращают её как *ApiError,
+// получить которую из обернутой ошибки можно через errors.As.
 type ApiError struct {
 	ErrorCode ErrorCode         `json:"errorCode"` // Код ошибки.
 	Message   string            `json:"message"`   // Описание ошибки.
 	Errors    []BadRequestError `json:"errors"`    // Присутствует, если ErrorCode = ErrBadRequest
+
+	StatusCode int    `json:"-"` // HTTP код ответа.
+	RequestID  string `json:"-"` // Идентификатор запроса (X-Origin-Request-Id).
+	Body       []byte `json:"-"` // Тело ответа.
 }
 
 type BadRequestError struct {
@@ -114,3 +134,107 @@ func (e ApiError) Error() string {
 
 	return message
 }
+
+// Is
+// Сопоставляет ошибку с константой ErrorCode, в том числе с кодами
+// вложенных ошибок валидации из поля Errors.
+func (e ApiError) Is(target error) bool {
+	code, ok := target.(ErrorCode)
+	if !ok {
+		return false
+	}
+
+	if e.ErrorCode == code {
+		return true
+	}
+
+	for _, requestError := range e.Errors {
+		if ErrorCode(requestError.ErrorCode) == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HTTPError
+// Ответ API с кодом ошибки, тело которого не удалось разобрать как ApiError
+// (например, HTML-страница шлюза или пустой ответ).
+type HTTPError struct {
+	StatusCode  int    // HTTP код ответа.
+	Status      string // HTTP статус ответа.
+	ContentType string // Тип содержимого ответа.
+	Body        []byte // Тело ответа.
+	RequestID   string // Идентификатор запроса (X-Origin-Request-Id).
+}
+
+func newHTTPError(resp *resty.Response) *HTTPError {
+	return &HTTPError{
+		StatusCode:  resp.StatusCode(),
+		Status:      resp.Status(),
+		ContentType: resp.Header().Get("Content-Type"),
+		Body:        resp.Body(),
+		RequestID:   RequestID(resp),
+	}
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("[%s]: unexpected response with content type '%s'", e.Status, e.ContentType)
+}
+
+// statusCode
+// HTTP код ответа, к которому относится ошибка, или 0, если ответ не был получен.
+func statusCode(err error) int {
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode
+	}
+
+	return 0
+}
+
+// IsRetryable
+// Ошибка временная, и запрос имеет смысл повторить: сетевая ошибка,
+// превышение лимита запросов (429) или ошибка сервера (5xx).
+// Отмена контекста и ошибки API с кодом 4xx повторяемыми не считаются.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if status := statusCode(err); status != 0 {
+		return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// IsClientError
+// Ошибка вызвана самим запросом (коды 4xx, в том числе все константы ErrorCode),
+// и повторять его без изменений бессмысленно.
+func IsClientError(err error) bool {
+	if status := statusCode(err); status != 0 {
+		return status >= http.StatusBadRequest && status < http.StatusInternalServerError
+	}
+
+	var apiErr *ApiError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode != ""
+}
+
+// IsAuthError
+// Ошибка аутентификации или доступа: неверный ID компании или API Key (ErrUnauthorized, 401)
+// либо отсутствие разрешения (ErrForbidden, 403).
+func IsAuthError(err error) bool {
+	if errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrForbidden) {
+		return true
+	}
+
+	status := statusCode(err)
+	return status == http.StatusUnauthorized || status == http.StatusForbidden
+}
@@ -0,0 +1,324 @@
+package uds
+
+import (
+	"fmt"
+	"time"
+)
+
+// Параллельные типы с суммами в Money и Points вместо float64.
+// Кодируются в JSON так же, как исходные типы, и преобразуются в них без потерь:
+// значение Money или Points в виде float64 имеет кратчайшее десятичное представление
+// с двумя знаками после запятой и передается в API без хвостов вида 99.99999.
+
+// ExactReceipt
+// Receipt с точными суммами.
+type ExactReceipt struct {
+	Total             Money   `json:"total"`                       // Сумма счета в денежных единицах.
+	Cash              Money   `json:"cash"`                        // Оплачиваемая сумма в денежных единицах.
+	Points            Points  `json:"points"`                      // Оплачиваемая сумма в бонусных баллах.
+	Number            *string `json:"number,omitempty"`            // Номер чека.
+	SkipLoyaltyTotal  *Money  `json:"skipLoyaltyTotal,omitempty"`  // Часть суммы счета, на которую не начисляется кешбэк и на которую не распространяется скидка (в денежных единицах).
+	UnredeemableTotal *Money  `json:"unredeemableTotal,omitempty"` // Часть суммы счета, которую нельзя погасить баллами.
+}
+
+// Exact
+// Чек с точными суммами: деньги округляются до сотых по RoundHalfUp, баллы - вниз.
+// Возвращает ошибку, если сумма равна NaN, бесконечности или не помещается в Money или Points.
+func (r Receipt) Exact() (ExactReceipt, error) {
+	var c exactConverter
+	exact := ExactReceipt{
+		Total:             c.money("total", r.Total),
+		Cash:              c.money("cash", r.Cash),
+		Points:            c.points("points", r.Points),
+		Number:            r.Number,
+		SkipLoyaltyTotal:  c.moneyPtr("skipLoyaltyTotal", r.SkipLoyaltyTotal),
+		UnredeemableTotal: c.moneyPtr("unredeemableTotal", r.UnredeemableTotal),
+	}
+	return exact, c.err
+}
+
+// Receipt
+// Чек для OperationCreate.
+func (r ExactReceipt) Receipt() Receipt {
+	return Receipt{
+		Total:             r.Total.Float64(),
+		Cash:              r.Cash.Float64(),
+		Points:            r.Points.Float64(),
+		Number:            r.Number,
+		SkipLoyaltyTotal:  moneyFloat(r.SkipLoyaltyTotal),
+		UnredeemableTotal: moneyFloat(r.UnredeemableTotal),
+	}
+}
+
+// ExactPurchaseDetail
+// PurchaseDetail с точными суммами. Проценты остаются float64.
+type ExactPurchaseDetail struct {
+	MaxPoints          Points  `json:"maxPoints"`          // Максимальное количество бонусных баллов, доступное для списания.
+	Total              Money   `json:"total"`              // Общая сумма счета (в денежных единицах).
+	SkipLoyaltyTotal   Money   `json:"skipLoyaltyTotal"`   // Часть суммы счета, на которую не начисляется кешбэк и на которую не распространяется скидка (в денежных единицах).
+	UnredeemableTotal  Money   `json:"unredeemableTotal"`  // Часть суммы счета, которую нельзя погасить баллами.
+	DiscountAmount     Money   `json:"discountAmount"`     // Размер скидки (в денежных единицах).
+	DiscountPercent    float64 `json:"discountPercent"`    // Предоставленная скидка (в процентах).
+	Points             Points  `json:"points"`             // Бонусных баллов к оплате.
+	PointsPercent      float64 `json:"pointsPercent"`      // Размер скидки за счет бонусных баллов (в процентах).
+	NetDiscount        Money   `json:"netDiscount"`        // Общий размер скидки (в денежных единицах).
+	NetDiscountPercent float64 `json:"netDiscountPercent"` // Общий размер скидки (в процентах от общей суммы счета).
+	CertificatePoints  Points  `json:"certificatePoints"`  // Количество списываемых бонусных баллов сертификата (в денежных единицах).
+	Cash               Money   `json:"cash"`               // Сумма к оплате (в денежных единицах).
+	CashTotal          Money   `json:"cashTotal"`          // Итоговая сумма к оплате с учетом доставки.
+	CashBack           Points  `json:"cashBack"`           // Вознаграждение (кешбэк), которое получит клиент после проведения операции (в бонусных баллах).
+	Extras             struct {
+		Delivery Money `json:"delivery"` // Стоимость доставки.
+	} `json:"extras"` // Дополнительный платежи, на которые не распространяется программа лояльности.
+	MaxScoresDiscount float64 `json:"maxScoresDiscount"` // Процент счета, который можно оплатить бонусными баллами.
+}
+
+// Exact
+// Информация об операции с точными суммами. Ошибки те же, что у Receipt.Exact.
+func (p PurchaseDetail) Exact() (ExactPurchaseDetail, error) {
+	var c exactConverter
+	exact := p.exact(&c, "")
+	return exact, c.err
+}
+
+// exact
+// Преобразование PurchaseDetail с префиксом prefix в именах полей для ошибок.
+func (p PurchaseDetail) exact(c *exactConverter, prefix string) ExactPurchaseDetail {
+	exact := ExactPurchaseDetail{
+		MaxPoints:          c.points(prefix+"maxPoints", p.MaxPoints),
+		Total:              c.money(prefix+"total", p.Total),
+		SkipLoyaltyTotal:   c.money(prefix+"skipLoyaltyTotal", p.SkipLoyaltyTotal),
+		UnredeemableTotal:  c.money(prefix+"unredeemableTotal", p.UnredeemableTotal),
+		DiscountAmount:     c.money(prefix+"discountAmount", p.DiscountAmount),
+		DiscountPercent:    p.DiscountPercent,
+		Points:             c.points(prefix+"points", p.Points),
+		PointsPercent:      p.PointsPercent,
+		NetDiscount:        c.money(prefix+"netDiscount", p.NetDiscount),
+		NetDiscountPercent: p.NetDiscountPercent,
+		CertificatePoints:  c.points(prefix+"certificatePoints", p.CertificatePoints),
+		Cash:               c.money(prefix+"cash", p.Cash),
+		CashTotal:          c.money(prefix+"cashTotal", p.CashTotal),
+		CashBack:           c.points(prefix+"cashBack", p.CashBack),
+		MaxScoresDiscount:  p.MaxScoresDiscount,
+	}
+	exact.Extras.Delivery = c.money(prefix+"extras.delivery", p.Extras.Delivery)
+	return exact
+}
+
+// PurchaseDetail
+// Информация об операции с суммами float64.
+func (p ExactPurchaseDetail) PurchaseDetail() PurchaseDetail {
+	detail := PurchaseDetail{
+		MaxPoints:          p.MaxPoints.Float64(),
+		Total:              p.Total.Float64(),
+		SkipLoyaltyTotal:   p.SkipLoyaltyTotal.Float64(),
+		UnredeemableTotal:  p.UnredeemableTotal.Float64(),
+		DiscountAmount:     p.DiscountAmount.Float64(),
+		DiscountPercent:    p.DiscountPercent,
+		Points:             p.Points.Float64(),
+		PointsPercent:      p.PointsPercent,
+		NetDiscount:        p.NetDiscount.Float64(),
+		NetDiscountPercent: p.NetDiscountPercent,
+		CertificatePoints:  p.CertificatePoints.Float64(),
+		Cash:               p.Cash.Float64(),
+		CashTotal:          p.CashTotal.Float64(),
+		CashBack:           p.CashBack.Float64(),
+		MaxScoresDiscount:  p.MaxScoresDiscount,
+	}
+	detail.Extras.Delivery = p.Extras.Delivery.Float64()
+	return detail
+}
+
+// ExactOperation
+// Operation с точными суммами.
+type ExactOperation struct {
+	Id            int64             `json:"id"`            // ID операции в базе UDS.
+	DateCreated   time.Time         `json:"dateCreated"`   // Дата операции.
+	Action        string            `json:"action"`        // Тип операции.
+	State         ActionState       `json:"state"`         // Статус операции.
+	Customer      CustomerShortInfo `json:"customer"`      // Информация о клиенте.
+	Cashier       Cashier           `json:"cashier"`       // Информация о сотруднике.
+	Branch        BranchInfo        `json:"branch"`        // Информация о филиале.
+	Points        Points            `json:"points"`        // Количество бонусных баллов. Отрицательное значение говорит о списании, а положительное - о начислении.
+	ReceiptNumber string            `json:"receiptNumber"` // Номер чека.
+	Origin        Origin            `json:"origin"`        // Для сторнирующей операции - ссылка на оригинальную операцию.
+	Total         Money             `json:"total"`         // Общая сумма чека до применения скидок в денежных единицах.
+	Cash          Money             `json:"cash"`          // Оплачиваемая сумма в денежных единицах.
+}
+
+// Exact
+// Операция с точными суммами. Ошибки те же, что у Receipt.Exact.
+func (o Operation) Exact() (ExactOperation, error) {
+	var c exactConverter
+	exact := ExactOperation{
+		Id:            o.Id,
+		DateCreated:   o.DateCreated,
+		Action:        o.Action,
+		State:         o.State,
+		Customer:      o.Customer,
+		Cashier:       o.Cashier,
+		Branch:        o.Branch,
+		Points:        c.points("points", o.Points),
+		ReceiptNumber: o.ReceiptNumber,
+		Origin:        o.Origin,
+		Total:         c.money("total", o.Total),
+		Cash:          c.money("cash", o.Cash),
+	}
+	return exact, c.err
+}
+
+// Operation
+// Операция с суммами float64.
+func (o ExactOperation) Operation() Operation {
+	return Operation{
+		Id:            o.Id,
+		DateCreated:   o.DateCreated,
+		Action:        o.Action,
+		State:         o.State,
+		Customer:      o.Customer,
+		Cashier:       o.Cashier,
+		Branch:        o.Branch,
+		Points:        o.Points.Float64(),
+		ReceiptNumber: o.ReceiptNumber,
+		Origin:        o.Origin,
+		Total:         o.Total.Float64(),
+		Cash:          o.Cash.Float64(),
+	}
+}
+
+// ExactGoodsOrderDetailed
+// GoodsOrderDetailed с точными суммами заказа и операции.
+type ExactGoodsOrderDetailed struct {
+	Id                int                 `json:"id"`                // ID заказа.
+	DateCreated       time.Time           `json:"dateCreated"`       // Дата заказа.
+	Comment           string              `json:"comment"`           // Комментарий к заказу.
+	State             GoodsOrderState     `json:"state"`             // Статус заказа.
+	Cash              Money               `json:"cash"`              // Сумма, оплачиваемая деньгами.
+	Points            Points              `json:"points"`            // Количество списываемых баллов.
+	Total             Money               `json:"total"`             // Сумма заказа.
+	CertificatePoints Points              `json:"certificatePoints"` // Количество списываемых баллов сертификата.
+	Customer          CustomerShortInfo   `json:"customer"`          // Информация о клиенте.
+	Delivery          Delivery            `json:"delivery"`          // Способ получения заказа.
+	OnlinePayment     OnlinePayment       `json:"onlinePayment"`     // Информация об онлайн-оплате.
+	PaymentMethod     PaymentMethod       `json:"paymentMethod"`     // Информация об оплате.
+	Items             []GoodOrderItem     `json:"items"`             // Информация о товарах.
+	Purchase          ExactPurchaseDetail `json:"purchase"`          // Информация об операции.
+}
+
+// Exact
+// Заказ с точными суммами. Ошибки те же, что у Receipt.Exact.
+func (o GoodsOrderDetailed) Exact() (ExactGoodsOrderDetailed, error) {
+	var c exactConverter
+	exact := ExactGoodsOrderDetailed{
+		Id:                o.Id,
+		DateCreated:       o.DateCreated,
+		Comment:           o.Comment,
+		State:             o.State,
+		Cash:              c.money("cash", o.Cash),
+		Points:            c.points("points", o.Points),
+		Total:             c.money("total", o.Total),
+		CertificatePoints: c.points("certificatePoints", o.CertificatePoints),
+		Customer:          o.Customer,
+		Delivery:          o.Delivery,
+		OnlinePayment:     o.OnlinePayment,
+		PaymentMethod:     o.PaymentMethod,
+		Items:             o.Items,
+		Purchase:          o.Purchase.exact(&c, "purchase."),
+	}
+	return exact, c.err
+}
+
+// GoodsOrderDetailed
+// Заказ с суммами float64.
+func (o ExactGoodsOrderDetailed) GoodsOrderDetailed() GoodsOrderDetailed {
+	return GoodsOrderDetailed{
+		Id:                o.Id,
+		DateCreated:       o.DateCreated,
+		Comment:           o.Comment,
+		State:             o.State,
+		Cash:              o.Cash.Float64(),
+		Points:            o.Points.Float64(),
+		Total:             o.Total.Float64(),
+		CertificatePoints: o.CertificatePoints.Float64(),
+		Customer:          o.Customer,
+		Delivery:          o.Delivery,
+		OnlinePayment:     o.OnlinePayment,
+		PaymentMethod:     o.PaymentMethod,
+		Items:             o.Items,
+		Purchase:          o.Purchase.PurchaseDetail(),
+	}
+}
+
+// ExactRewardOperationRequest
+// RewardOperationRequest с точным количеством баллов.
+type ExactRewardOperationRequest struct {
+	Points       Points  `json:"points"`            // Количество бонусных баллов. (Можем иметь отрицательное значение - списание)
+	Comment      string  `json:"comment,omitempty"` // Текст комментария, который увидит пользователь.
+	Participants []int64 `json:"participants"`      // Список ID клиентов в компании.
+	Silent       bool    `json:"silent"`            // Не отправлять пуш-уведомление клиенту (default false).
+}
+
+// Exact
+// Запрос на начисление с количеством баллов, округленным вниз до сотых.
+// Ошибки те же, что у Receipt.Exact.
+func (r RewardOperationRequest) Exact() (ExactRewardOperationRequest, error) {
+	var c exactConverter
+	exact := ExactRewardOperationRequest{
+		Points:       c.points("points", r.Points),
+		Comment:      r.Comment,
+		Participants: r.Participants,
+		Silent:       r.Silent,
+	}
+	return exact, c.err
+}
+
+// RewardOperationRequest
+// Запрос для OperationReward.
+func (r ExactRewardOperationRequest) RewardOperationRequest() RewardOperationRequest {
+	return RewardOperationRequest{
+		Points:       r.Points.Float64(),
+		Comment:      r.Comment,
+		Participants: r.Participants,
+		Silent:       r.Silent,
+	}
+}
+
+// exactConverter
+// Преобразует поля float64 в Money (RoundHalfUp) и Points, запоминая первую ошибку с именем поля.
+type exactConverter struct {
+	err error
+}
+
+func (c *exactConverter) money(field string, value float64) Money {
+	money, err := MoneyFromFloat(value, RoundHalfUp)
+	c.check(field, err)
+	return money
+}
+
+func (c *exactConverter) moneyPtr(field string, value *float64) *Money {
+	if value == nil {
+		return nil
+	}
+	money := c.money(field, *value)
+	return &money
+}
+
+func (c *exactConverter) points(field string, value float64) Points {
+	points, err := PointsFromFloat(value)
+	c.check(field, err)
+	return points
+}
+
+func (c *exactConverter) check(field string, err error) {
+	if err != nil && c.err == nil {
+		c.err = fmt.Errorf("uds: %s: %w", field, err)
+	}
+}
+
+func moneyFloat(value *Money) *float64 {
+	if value == nil {
+		return nil
+	}
+	f := value.Float64()
+	return &f
+}
@@ -0,0 +1,229 @@
+package uds
+
+import (
+	"context"
+	"github.com/go-resty/resty/v2"
+	"strconv"
+	"time"
+)
+
+const GoodsListMaxPageSize = 50 // Максимальное значение параметра max в GoodsGetList.
+
+// GoodsNode
+// Элемент каталога товаров: товар (ITEM), вариативный товар (VARYING_ITEM) или категория (CATEGORY).
+type GoodsNode struct {
+	Id          int64     `json:"id"`                   // ID элемента в UDS.
+	Name        string    `json:"name"`                 // Название.
+	NodeId      *int64    `json:"nodeId,omitempty"`     // ID родительской категории. nil - корень каталога.
+	ExternalId  string    `json:"externalId,omitempty"` // Внешний идентификатор.
+	Hidden      bool      `json:"hidden"`               // Элемент скрыт в приложении.
+	DateCreated time.Time `json:"dateCreated"`          // Дата создания.
+	Data        GoodsData `json:"data"`                 // Данные товара или категории.
+}
+
+// Request
+// Запрос на изменение элемента с теми же данными, например, для GoodsUpdate после правки полей.
+func (n GoodsNode) Request() GoodsNodeRequest {
+	return GoodsNodeRequest{
+		Name:       n.Name,
+		NodeId:     n.NodeId,
+		ExternalId: n.ExternalId,
+		Hidden:     n.Hidden,
+		Data:       n.Data,
+	}
+}
+
+// GoodsData
+// Данные товара или категории. Для категории заполняется только Type.
+type GoodsData struct {
+	Type        GoodsItemType    `json:"type"`                  // Тип элемента.
+	Price       float64          `json:"price,omitempty"`       // Цена товара (ITEM).
+	Sku         string           `json:"sku,omitempty"`         // Артикул товара (ITEM).
+	Description string           `json:"description,omitempty"` // Описание.
+	Photos      []string         `json:"photos,omitempty"`      // Ссылки на фотографии.
+	Measurement GoodsMeasurement `json:"measurement,omitempty"` // Единицы измерения.
+	Increment   float64          `json:"increment,omitempty"`   // Шаг изменения количества.
+	MinQuantity float64          `json:"minQuantity,omitempty"` // Минимальное количество для заказа.
+	Offer       *GoodsOffer      `json:"offer,omitempty"`       // Акционная цена (ITEM).
+	Inventory   *GoodsInventory  `json:"inventory,omitempty"`   // Остаток (ITEM).
+	Variants    []GoodsVariant   `json:"variants,omitempty"`    // Варианты товара (VARYING_ITEM).
+}
+
+// GoodsOffer
+// Акционная цена.
+type GoodsOffer struct {
+	OfferPrice  float64 `json:"offerPrice"`  // Цена по акции.
+	SkipLoyalty bool    `json:"skipLoyalty"` // Не применять бонусную программу к товару.
+}
+
+// GoodsInventory
+// Остаток товара.
+type GoodsInventory struct {
+	InStock *float64 `json:"inStock"` // Количество на складе. nil - остаток не учитывается.
+}
+
+// GoodsVariant
+// Вариант вариативного товара.
+type GoodsVariant struct {
+	Name      string          `json:"name"`                // Название варианта.
+	Sku       string          `json:"sku,omitempty"`       // Артикул варианта.
+	Price     float64         `json:"price"`               // Цена варианта.
+	Offer     *GoodsOffer     `json:"offer,omitempty"`     // Акционная цена варианта.
+	Inventory *GoodsInventory `json:"inventory,omitempty"` // Остаток варианта.
+}
+
+// GoodsNodeRequest
+// Объект запроса на создание или изменение элемента каталога.
+type GoodsNodeRequest struct {
+	Name       string    `json:"name"`                 // Название.
+	NodeId     *int64    `json:"nodeId,omitempty"`     // ID родительской категории. Не указывается для корня каталога.
+	ExternalId string    `json:"externalId,omitempty"` // Внешний идентификатор.
+	Hidden     bool      `json:"hidden"`               // Скрыть элемент в приложении.
+	Data       GoodsData `json:"data"`                 // Данные товара или категории.
+}
+
+func (r *GoodsNodeRequest) SetNodeId(nodeId int64) *GoodsNodeRequest {
+	r.NodeId = &nodeId
+	return r
+}
+
+// GoodsList
+// Список элементов каталога.
+type GoodsList struct {
+	Rows  []GoodsNode `json:"rows"`  // Элементы каталога.
+	Total int         `json:"total"` // Общее количество элементов.
+}
+
+// GoodsGetList
+// Получить список элементов каталога в категории nodeId (0 - корень каталога)
+// https://docs.uds.app/#tag/Goods/paths/~1goods/get
+func (u *Client) GoodsGetList(maxValue int, offset int, nodeId int64) (*GoodsList, *resty.Response, error) {
+	return u.GoodsGetListWithContext(context.Background(), maxValue, offset, nodeId)
+}
+
+// GoodsGetListWithContext
+// Получить список элементов каталога в категории nodeId (0 - корень каталога) с учетом контекста ctx
+// https://docs.uds.app/#tag/Goods/paths/~1goods/get
+func (u *Client) GoodsGetListWithContext(ctx context.Context, maxValue int, offset int, nodeId int64) (*GoodsList, *resty.Response, error) {
+	goods := new(GoodsList)
+
+	req := u.newRequest(ctx)
+
+	if maxValue > 0 {
+		maxValue = max(1, min(GoodsListMaxPageSize, maxValue)) // от 1 до 50
+		req.SetQueryParam("max", strconv.Itoa(maxValue))
+	}
+
+	if offset > 0 {
+		req.SetQueryParam("offset", strconv.Itoa(offset))
+	}
+
+	if nodeId > 0 {
+		req.SetQueryParam("nodeId", strconv.FormatInt(nodeId, 10))
+	}
+
+	resp, err := u.execute("GoodsGetList", req.SetResult(goods), resty.MethodGet, "goods")
+
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return goods, resp, nil
+}
+
+// GoodsGetByID
+// Получить элемент каталога
+// https://docs.uds.app/#tag/Goods/paths/~1goods~1{id}/get
+func (u *Client) GoodsGetByID(id int64) (*GoodsNode, *resty.Response, error) {
+	return u.GoodsGetByIDWithContext(context.Background(), id)
+}
+
+// GoodsGetByIDWithContext
+// Получить элемент каталога с учетом контекста ctx
+// https://docs.uds.app/#tag/Goods/paths/~1goods~1{id}/get
+func (u *Client) GoodsGetByIDWithContext(ctx context.Context, id int64) (*GoodsNode, *resty.Response, error) {
+	node := new(GoodsNode)
+
+	req := u.newRequest(ctx).
+		SetPathParam("id", strconv.FormatInt(id, 10)).
+		SetResult(node)
+
+	resp, err := u.execute("GoodsGetByID", req, resty.MethodGet, "goods/{id}")
+
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return node, resp, nil
+}
+
+// GoodsCreate
+// Создать товар или категорию.
+// При превышении лимита товаров возвращается ErrGoodsLimitIsReached,
+// при неверной родительской категории - ErrGoodsNodeIndexInvalid.
+// https://docs.uds.app/#tag/Goods/paths/~1goods/post
+func (u *Client) GoodsCreate(node *GoodsNodeRequest) (*GoodsNode, *resty.Response, error) {
+	return u.GoodsCreateWithContext(context.Background(), node)
+}
+
+// GoodsCreateWithContext
+// Создать товар или категорию с учетом контекста ctx
+// https://docs.uds.app/#tag/Goods/paths/~1goods/post
+func (u *Client) GoodsCreateWithContext(ctx context.Context, node *GoodsNodeRequest) (*GoodsNode, *resty.Response, error) {
+	created := new(GoodsNode)
+
+	req := u.newRequest(ctx).
+		SetBody(node).
+		SetResult(created)
+
+	resp, err := u.execute("GoodsCreate", req, resty.MethodPost, "goods")
+
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return created, resp, nil
+}
+
+// GoodsUpdate
+// Изменить товар или категорию
+// https://docs.uds.app/#tag/Goods/paths/~1goods~1{id}/put
+func (u *Client) GoodsUpdate(id int64, node *GoodsNodeRequest) (*GoodsNode, *resty.Response, error) {
+	return u.GoodsUpdateWithContext(context.Background(), id, node)
+}
+
+// GoodsUpdateWithContext
+// Изменить товар или категорию с учетом контекста ctx
+// https://docs.uds.app/#tag/Goods/paths/~1goods~1{id}/put
+func (u *Client) GoodsUpdateWithContext(ctx context.Context, id int64, node *GoodsNodeRequest) (*GoodsNode, *resty.Response, error) {
+	updated := new(GoodsNode)
+
+	req := u.newRequest(ctx).
+		SetPathParam("id", strconv.FormatInt(id, 10)).
+		SetBody(node).
+		SetResult(updated)
+
+	resp, err := u.execute("GoodsUpdate", req, resty.MethodPut, "goods/{id}")
+
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return updated, resp, nil
+}
+
+// GoodsDelete
+// Удалить товар или категорию
+// https://docs.uds.app/#tag/Goods/paths/~1goods~1{id}/delete
+func (u *Client) GoodsDelete(id int64) (*resty.Response, error) {
+	return u.GoodsDeleteWithContext(context.Background(), id)
+}
+
+// GoodsDeleteWithContext
+// Удалить товар или категорию с учетом контекста ctx
+// https://docs.uds.app/#tag/Goods/paths/~1goods~1{id}/delete
+func (u *Client) GoodsDeleteWithContext(ctx context.Context, id int64) (*resty.Response, error) {
+	req := u.newRequest(ctx).SetPathParam("id", strconv.FormatInt(id, 10))
+
+	return u.execute("GoodsDelete", req, resty.MethodDelete, "goods/{id}")
+}
@@ -1,11 +1,14 @@
 package uds
 
 import (
+	"context"
 	"github.com/go-resty/resty/v2"
 	"strconv"
 	"time"
 )
 
+const GoodsOrderListMaxPageSize = 50 // Максимальное значение параметра max в GoodsOrderGetList.
+
 // GoodsOrderState
 // Статус заказа.
 type GoodsOrderState string
@@ -99,6 +102,7 @@ type GoodsItemType string
 const (
 	GoodsItemTypeItem        GoodsItemType = "ITEM"
 	GoodsItemTypeVaryingItem GoodsItemType = "VARYING_ITEM"
+	GoodsItemTypeCategory    GoodsItemType = "CATEGORY"
 )
 
 // GoodsMeasurement
@@ -133,13 +137,21 @@ type GoodOrderItem struct {
 // Подробная информация о заказе
 // https://docs.uds.app/#tag/Goods-Order/paths/~1goods-orders~1{id}/get
 func (u *Client) GoodsOrderGetByID(id int64) (*GoodsOrderDetailed, *resty.Response, error) {
+	return u.GoodsOrderGetByIDWithContext(context.Background(), id)
+}
+
+// GoodsOrderGetByIDWithContext
+// Подробная информация о заказе с учетом контекста ctx
+// https://docs.uds.app/#tag/Goods-Order/paths/~1goods-orders~1{id}/get
+func (u *Client) GoodsOrderGetByIDWithContext(ctx context.Context, id int64) (*GoodsOrderDetailed, *resty.Response, error) {
 	goodsOrder := new(GoodsOrderDetailed)
 
 	idString := strconv.FormatInt(id, 10)
 
-	resp, err := u.client.R().SetPathParam("id", idString).
-		SetResult(goodsOrder).
-		Get("goods-orders/{id}")
+	req := u.newRequest(ctx).SetPathParam("id", idString).
+		SetResult(goodsOrder)
+
+	resp, err := u.execute("GoodsOrderGetByID", req, resty.MethodGet, "goods-orders/{id}")
 
 	if err != nil {
 		return nil, resp, err
@@ -148,6 +160,87 @@ func (u *Client) GoodsOrderGetByID(id int64) (*GoodsOrderDetailed, *resty.Respon
 	return goodsOrder, resp, nil
 }
 
+// GoodsOrderList
+// Список заказов.
+type GoodsOrderList struct {
+	Rows  []GoodsOrderDetailed `json:"rows"`  // Информация о заказах.
+	Total int                  `json:"total"` // Общее количество заказов.
+}
+
+// GoodsOrderFilter
+// Критерии отбора заказов. Параметры фильтрации GET /goods-orders в документации API не описаны,
+// поэтому критерии применяются на стороне клиента к каждому полученному заказу:
+// см. WithGoodsOrderFilter и GoodsOrderFilter.Filter.
+// Незаполненные поля не ограничивают выборку, заполненные объединяются по И,
+// значения внутри одного поля - по ИЛИ.
+type GoodsOrderFilter struct {
+	States    []GoodsOrderState // Статусы заказа.
+	From      time.Time         // Дата заказа не раньше From (включительно).
+	To        time.Time         // Дата заказа раньше To (не включительно).
+	BranchIDs []int64           // ID филиалов (Delivery.Branch.Id).
+}
+
+// Match
+// Удовлетворяет ли заказ всем критериям фильтра.
+func (f GoodsOrderFilter) Match(order GoodsOrderDetailed) bool {
+	if !f.From.IsZero() && order.DateCreated.Before(f.From) {
+		return false
+	}
+
+	if !f.To.IsZero() && !order.DateCreated.Before(f.To) {
+		return false
+	}
+
+	return matchAny(f.States, order.State) &&
+		matchAny(f.BranchIDs, order.Delivery.Branch.Id)
+}
+
+// Filter
+// Заказы из orders, удовлетворяющие фильтру.
+func (f GoodsOrderFilter) Filter(orders []GoodsOrderDetailed) []GoodsOrderDetailed {
+	var filtered []GoodsOrderDetailed
+	for _, order := range orders {
+		if f.Match(order) {
+			filtered = append(filtered, order)
+		}
+	}
+	return filtered
+}
+
+// GoodsOrderGetList
+// Получить список заказов, от новых к старым.
+// https://docs.uds.app/#tag/Goods-Order/paths/~1goods-orders/get
+func (u *Client) GoodsOrderGetList(maxValue int, offset int) (*GoodsOrderList, *resty.Response, error) {
+	return u.GoodsOrderGetListWithContext(context.Background(), maxValue, offset)
+}
+
+// GoodsOrderGetListWithContext
+// Получить список заказов, от новых к старым, с учетом контекста ctx.
+// Для отбора заказов используйте GoodsOrderFilter.Filter.
+// https://docs.uds.app/#tag/Goods-Order/paths/~1goods-orders/get
+func (u *Client) GoodsOrderGetListWithContext(ctx context.Context, maxValue int, offset int) (*GoodsOrderList, *resty.Response, error) {
+	goodsOrders := new(GoodsOrderList)
+
+	req := u.newRequest(ctx)
+
+	if maxValue > 0 {
+		maxValue = max(1, min(GoodsOrderListMaxPageSize, maxValue)) // от 1 до 50
+		req.SetQueryParam("max", strconv.Itoa(maxValue))
+	}
+
+	if offset > 0 {
+		req.SetQueryParam("offset", strconv.Itoa(offset))
+	}
+
+	resp, err := u.execute("GoodsOrderGetList", req.SetResult(goodsOrders), resty.MethodGet, "goods-orders")
+
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return goodsOrders, resp, nil
+}
+
 // DeliveryCase
 // Информация о доставке.
 type DeliveryCase struct {
@@ -181,10 +274,12 @@ type UpdateGoodsOrderRequest[T GoodsOrderItemUpdate | GoodsOrderItemNew] struct
 	Items        []T          `json:"items"`        // Информация о товарах.
 }
 
-func updateGoodsOrderItemsProcess(req *resty.Request, id int64) (*GoodsOrderDetailed, *resty.Response, error) {
+func (u *Client) updateGoodsOrderItemsProcess(operation string, req *resty.Request, id int64) (*GoodsOrderDetailed, *resty.Response, error) {
 	goodsOrder := new(GoodsOrderDetailed)
 	idString := strconv.FormatInt(id, 10)
-	resp, err := req.SetPathParam("id", idString).SetResult(goodsOrder).Put("goods-orders/{id}")
+	req.SetPathParam("id", idString).SetResult(goodsOrder)
+
+	resp, err := u.execute(operation, req, resty.MethodPut, "goods-orders/{id}")
 
 	if err != nil {
 		return nil, resp, err
@@ -197,16 +292,30 @@ func updateGoodsOrderItemsProcess(req *resty.Request, id int64) (*GoodsOrderDeta
 // Изменить товары заказа
 // https://docs.uds.app/#tag/Goods-Order/paths/~1goods-orders~1{id}/put
 func (u *Client) GoodsOrderUpdateItems(id int64, updatedOrder *UpdateGoodsOrderRequest[GoodsOrderItemUpdate]) (*GoodsOrderDetailed, *resty.Response, error) {
-	req := u.client.R().SetBody(updatedOrder)
-	return updateGoodsOrderItemsProcess(req, id)
+	return u.GoodsOrderUpdateItemsWithContext(context.Background(), id, updatedOrder)
+}
+
+// GoodsOrderUpdateItemsWithContext
+// Изменить товары заказа с учетом контекста ctx
+// https://docs.uds.app/#tag/Goods-Order/paths/~1goods-orders~1{id}/put
+func (u *Client) GoodsOrderUpdateItemsWithContext(ctx context.Context, id int64, updatedOrder *UpdateGoodsOrderRequest[GoodsOrderItemUpdate]) (*GoodsOrderDetailed, *resty.Response, error) {
+	req := u.newRequest(ctx).SetBody(updatedOrder)
+	return u.updateGoodsOrderItemsProcess("GoodsOrderUpdateItems", req, id)
 }
 
 // GoodsOrderAddItems
 // Добавить товары в заказ
 // https://docs.uds.app/#tag/Goods-Order/paths/~1goods-orders~1{id}/put
 func (u *Client) GoodsOrderAddItems(id int64, updatedOrder *UpdateGoodsOrderRequest[GoodsOrderItemNew]) (*GoodsOrderDetailed, *resty.Response, error) {
-	req := u.client.R().SetBody(updatedOrder)
-	return updateGoodsOrderItemsProcess(req, id)
+	return u.GoodsOrderAddItemsWithContext(context.Background(), id, updatedOrder)
+}
+
+// GoodsOrderAddItemsWithContext
+// Добавить товары в заказ с учетом контекста ctx
+// https://docs.uds.app/#tag/Goods-Order/paths/~1goods-orders~1{id}/put
+func (u *Client) GoodsOrderAddItemsWithContext(ctx context.Context, id int64, updatedOrder *UpdateGoodsOrderRequest[GoodsOrderItemNew]) (*GoodsOrderDetailed, *resty.Response, error) {
+	req := u.newRequest(ctx).SetBody(updatedOrder)
+	return u.updateGoodsOrderItemsProcess("GoodsOrderAddItems", req, id)
 }
 
 // CompleteGoodsOrder
@@ -222,13 +331,21 @@ type CompleteGoodsOrder struct {
 // Завершает заказ товара с идентификатором и создает транзакцию
 // https://docs.uds.app/#tag/Goods-Order/paths/~1goods-orders~1{id}~1complete/post
 func (u *Client) GoodsOrderComplete(id int64) (*CompleteGoodsOrder, *resty.Response, error) {
+	return u.GoodsOrderCompleteWithContext(context.Background(), id)
+}
+
+// GoodsOrderCompleteWithContext
+// Завершает заказ товара с идентификатором и создает транзакцию с учетом контекста ctx
+// https://docs.uds.app/#tag/Goods-Order/paths/~1goods-orders~1{id}~1complete/post
+func (u *Client) GoodsOrderCompleteWithContext(ctx context.Context, id int64) (*CompleteGoodsOrder, *resty.Response, error) {
 	completeGoodsOrder := new(CompleteGoodsOrder)
 
 	idString := strconv.FormatInt(id, 10)
 
-	resp, err := u.client.R().SetPathParam("id", idString).
-		SetResult(completeGoodsOrder).
-		Post("goods-orders/{id}/complete")
+	req := u.newRequest(ctx).SetPathParam("id", idString).
+		SetResult(completeGoodsOrder)
+
+	resp, err := u.execute("GoodsOrderComplete", req, resty.MethodPost, "goods-orders/{id}/complete")
 
 	if err != nil {
 		return nil, resp, err
@@ -241,6 +358,13 @@ func (u *Client) GoodsOrderComplete(id int64) (*CompleteGoodsOrder, *resty.Respo
 // Сгенерировать код для завершения заказа товара с идентификатором
 // https://docs.uds.app/#tag/Goods-Order/paths/~1goods-orders~1{id}~1code/post
 func (u *Client) GoodsOrderGenerateCode(id int64) (string, *resty.Response, error) {
+	return u.GoodsOrderGenerateCodeWithContext(context.Background(), id)
+}
+
+// GoodsOrderGenerateCodeWithContext
+// Сгенерировать код для завершения заказа товара с идентификатором с учетом контекста ctx
+// https://docs.uds.app/#tag/Goods-Order/paths/~1goods-orders~1{id}~1code/post
+func (u *Client) GoodsOrderGenerateCodeWithContext(ctx context.Context, id int64) (string, *resty.Response, error) {
 	type s struct {
 		Code string `json:"code"`
 	}
@@ -248,9 +372,10 @@ func (u *Client) GoodsOrderGenerateCode(id int64) (string, *resty.Response, erro
 
 	idString := strconv.FormatInt(id, 10)
 
-	resp, err := u.client.R().SetPathParam("id", idString).
-		SetResult(code).
-		Post("goods-orders/{id}/code")
+	req := u.newRequest(ctx).SetPathParam("id", idString).
+		SetResult(code)
+
+	resp, err := u.execute("GoodsOrderGenerateCode", req, resty.MethodPost, "goods-orders/{id}/code")
 
 	if err != nil {
 		return "", resp, err
@@ -0,0 +1,333 @@
+package uds
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+const DefaultGoodsOrderPollInterval = 30 * time.Second // Интервал опроса заказов GoodsOrderPoller по умолчанию.
+
+// GoodsOrderEvent
+// Событие GoodsOrderPoller: OrderCreated, OrderStateChanged или OrderPaid.
+//
+//	switch event := poller.Event().(type) {
+//	case uds.OrderCreated:
+//		...
+//	case uds.OrderStateChanged:
+//		...
+//	case uds.OrderPaid:
+//		...
+//	}
+type GoodsOrderEvent interface {
+	GoodsOrder() GoodsOrderDetailed // Заказ в состоянии на момент события.
+	goodsOrderEvent()
+}
+
+// OrderCreated
+// Появился новый заказ.
+type OrderCreated struct {
+	Order GoodsOrderDetailed
+}
+
+// OrderStateChanged
+// Изменился статус заказа.
+type OrderStateChanged struct {
+	Order    GoodsOrderDetailed
+	Previous GoodsOrderState // Статус заказа до изменения.
+}
+
+// OrderPaid
+// Завершена онлайн-оплата заказа (OnlinePayment.Completed изменился на true).
+type OrderPaid struct {
+	Order GoodsOrderDetailed
+}
+
+func (e OrderCreated) GoodsOrder() GoodsOrderDetailed      { return e.Order }
+func (e OrderStateChanged) GoodsOrder() GoodsOrderDetailed { return e.Order }
+func (e OrderPaid) GoodsOrder() GoodsOrderDetailed         { return e.Order }
+
+func (OrderCreated) goodsOrderEvent()      {}
+func (OrderStateChanged) goodsOrderEvent() {}
+func (OrderPaid) goodsOrderEvent()         {}
+
+// GoodsOrderPoller
+// Периодический опрос списка заказов и выдача событий об их изменениях.
+// Первый опрос запоминает уже существующие заказы без событий (см. WithExistingGoodsOrders).
+// Заказ, который перестал удовлетворять фильтру (например, его статус больше не входит в States),
+// выдает последнее событие об изменении статуса и после этого больше не отслеживается.
+// Так же после события перестает отслеживаться заказ в итоговом статусе (COMPLETED, DELETED).
+// Отслеживаемый заказ, пропавший из списка, запрашивается по ID.
+//
+// API не фильтрует список заказов и отдает его от новых к старым, а фильтр применяется на стороне клиента.
+// Опрос читает список, пока не дойдет до заказов старше самого старого отслеживаемого заказа
+// и самого нового заказа предыдущего опроса, но не раньше GoodsOrderFilter.From.
+// Первый опрос без From читает весь список. Поэтому заказ, созданный раньше всех отслеживаемых
+// и не удовлетворявший фильтру, не попадет в события, если позже начнет ему удовлетворять.
+//
+//	poller := uds.NewGoodsOrderPoller(client, uds.WithGoodsOrderFilter(uds.GoodsOrderFilter{
+//		States: []uds.GoodsOrderState{uds.GoodsOrderStateNew, uds.GoodsOrderStateWaitingPayment},
+//	}))
+//	for poller.Next(ctx) {
+//		event := poller.Event()
+//		...
+//	}
+//	if err := poller.Err(); err != nil { ... }
+type GoodsOrderPoller struct {
+	orders       GoodsOrdersAPI
+	filter       GoodsOrderFilter
+	pollInterval time.Duration
+	existing     bool
+
+	primed  bool
+	known   map[int]goodsOrderSnapshot // ID заказа -> состояние при последнем опросе.
+	newest  time.Time                  // Дата самого нового заказа в списке при последнем опросе.
+	events  []GoodsOrderEvent
+	current GoodsOrderEvent
+	err     error
+}
+
+// goodsOrderSnapshot
+// Отслеживаемое состояние заказа.
+type goodsOrderSnapshot struct {
+	state   GoodsOrderState
+	paid    bool
+	created time.Time
+}
+
+// GoodsOrderPollerOption
+// Функциональная опция для NewGoodsOrderPoller
+type GoodsOrderPollerOption func(*GoodsOrderPoller)
+
+// WithGoodsOrderFilter
+// Отслеживать только заказы, удовлетворяющие фильтру.
+func WithGoodsOrderFilter(filter GoodsOrderFilter) GoodsOrderPollerOption {
+	return func(p *GoodsOrderPoller) {
+		p.filter = filter
+	}
+}
+
+// WithGoodsOrderPollInterval
+// Интервал между опросами. Значение interval <= 0 означает DefaultGoodsOrderPollInterval.
+func WithGoodsOrderPollInterval(interval time.Duration) GoodsOrderPollerOption {
+	return func(p *GoodsOrderPoller) {
+		if interval <= 0 {
+			interval = DefaultGoodsOrderPollInterval
+		}
+		p.pollInterval = interval
+	}
+}
+
+// WithExistingGoodsOrders
+// Выдать OrderCreated для заказов, найденных при первом опросе.
+func WithExistingGoodsOrders(existing bool) GoodsOrderPollerOption {
+	return func(p *GoodsOrderPoller) {
+		p.existing = existing
+	}
+}
+
+// NewGoodsOrderPoller
+// Создает опрос заказов.
+func NewGoodsOrderPoller(orders GoodsOrdersAPI, opts ...GoodsOrderPollerOption) *GoodsOrderPoller {
+	p := &GoodsOrderPoller{
+		orders:       orders,
+		pollInterval: DefaultGoodsOrderPollInterval,
+		known:        map[int]goodsOrderSnapshot{},
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Next
+// Переходит к следующему событию, при необходимости выполняя опросы с интервалом опроса.
+// Возвращает false, когда опрос прерван отменой ctx или произошла ошибка.
+func (p *GoodsOrderPoller) Next(ctx context.Context) bool {
+	if p.err != nil {
+		return false
+	}
+
+	for len(p.events) == 0 {
+		if p.primed {
+			if err := sleep(ctx, p.pollInterval); err != nil {
+				p.err = err
+				return false
+			}
+		}
+
+		events, err := p.Poll(ctx)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		p.events = events
+	}
+
+	p.current = p.events[0]
+	p.events = p.events[1:]
+	return true
+}
+
+// Event
+// Текущее событие после успешного вызова Next.
+func (p *GoodsOrderPoller) Event() GoodsOrderEvent {
+	return p.current
+}
+
+// Err
+// Ошибка, остановившая опрос.
+func (p *GoodsOrderPoller) Err() error {
+	return p.err
+}
+
+// Poll
+// Выполняет один опрос и возвращает события, произошедшие с предыдущего опроса,
+// в порядке даты создания заказов. Используется вместо Next, если опрос запускается по собственному расписанию.
+func (p *GoodsOrderPoller) Poll(ctx context.Context) ([]GoodsOrderEvent, error) {
+	orders, err := p.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(orders, func(a, b GoodsOrderDetailed) int {
+		if c := a.DateCreated.Compare(b.DateCreated); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Id, b.Id)
+	})
+
+	var events []GoodsOrderEvent
+	seen := make(map[int]bool, len(orders))
+
+	for _, order := range orders {
+		if seen[order.Id] {
+			continue
+		}
+		seen[order.Id] = true
+
+		if p.filter.Match(order) {
+			_, ok := p.known[order.Id]
+			if !ok && p.primed && order.State.Final() && !order.DateCreated.After(p.newest) {
+				// Заказ, переставший отслеживаться после итогового статуса.
+				continue
+			}
+
+			events = p.observe(events, order, !p.primed && !p.existing)
+			if order.State.Final() {
+				delete(p.known, order.Id)
+			}
+			continue
+		}
+
+		// Заказ перестал удовлетворять фильтру: последнее событие, после которого он не отслеживается.
+		if _, ok := p.known[order.Id]; ok {
+			events = p.observe(events, order, false)
+			delete(p.known, order.Id)
+		}
+	}
+
+	if p.primed {
+		for id := range p.known {
+			if seen[id] {
+				continue
+			}
+
+			order, _, err := p.orders.GoodsOrderGetByIDWithContext(ctx, int64(id))
+			if errors.Is(err, ErrNotFound) {
+				delete(p.known, id)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			events = p.observe(events, *order, false)
+			if !p.filter.Match(*order) || order.State.Final() {
+				delete(p.known, id)
+			}
+		}
+	}
+
+	if len(orders) > 0 && orders[len(orders)-1].DateCreated.After(p.newest) {
+		p.newest = orders[len(orders)-1].DateCreated
+	}
+	p.primed = true
+	return events, nil
+}
+
+// observe
+// Сравнивает заказ с его состоянием при предыдущем опросе и добавляет события к events.
+func (p *GoodsOrderPoller) observe(events []GoodsOrderEvent, order GoodsOrderDetailed, silent bool) []GoodsOrderEvent {
+	current := goodsOrderSnapshot{state: order.State, paid: order.OnlinePayment.Completed, created: order.DateCreated}
+	previous, ok := p.known[order.Id]
+	p.known[order.Id] = current
+
+	if silent {
+		return events
+	}
+
+	if !ok {
+		return append(events, OrderCreated{Order: order})
+	}
+
+	if current.state != previous.state {
+		events = append(events, OrderStateChanged{Order: order, Previous: previous.state})
+	}
+
+	if current.paid && !previous.paid {
+		events = append(events, OrderPaid{Order: order})
+	}
+
+	return events
+}
+
+// list
+// Заказы не старше since (см. GoodsOrderPoller). Фильтр применяется в Poll.
+func (p *GoodsOrderPoller) list(ctx context.Context) ([]GoodsOrderDetailed, error) {
+	since := p.since()
+	var orders []GoodsOrderDetailed
+
+	for offset := 0; ; {
+		list, _, err := p.orders.GoodsOrderGetListWithContext(ctx, GoodsOrderListMaxPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, list.Rows...)
+		offset += len(list.Rows)
+
+		if len(list.Rows) < GoodsOrderListMaxPageSize || (list.Total > 0 && offset >= list.Total) {
+			return orders, nil
+		}
+
+		if !since.IsZero() && list.Rows[len(list.Rows)-1].DateCreated.Before(since) {
+			return orders, nil
+		}
+	}
+}
+
+// since
+// Дата, старше которой заказы не нужны опросу: самый старый отслеживаемый заказ
+// или самый новый заказ предыдущего опроса, но не раньше GoodsOrderFilter.From.
+// Нулевое значение означает весь список.
+func (p *GoodsOrderPoller) since() time.Time {
+	var since time.Time
+	if p.primed {
+		since = p.newest
+		for _, snapshot := range p.known {
+			if snapshot.created.Before(since) {
+				since = snapshot.created
+			}
+		}
+	}
+
+	if p.filter.From.After(since) {
+		since = p.filter.From
+	}
+	return since
+}
@@ -0,0 +1,81 @@
+package uds_test
+
+import (
+	"context"
+	"github.com/arcsub/go-uds/uds"
+	"github.com/arcsub/go-uds/uds/udsmock"
+	"github.com/go-resty/resty/v2"
+	"testing"
+	"time"
+)
+
+func TestGoodsOrderPollerStopsPagingAndDropsFinalOrders(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// 120 заказов от новых к старым: 101-120 новые, остальные выполнены.
+	var orders []uds.GoodsOrderDetailed
+	for id := 120; id >= 1; id-- {
+		state := uds.GoodsOrderStateCompleted
+		if id > 100 {
+			state = uds.GoodsOrderStateNew
+		}
+		orders = append(orders, uds.GoodsOrderDetailed{Id: id, State: state, DateCreated: base.Add(time.Duration(id) * time.Minute)})
+	}
+
+	pages := 0
+	mock := &udsmock.Mock{
+		GoodsOrderGetListFunc: func(ctx context.Context, maxValue int, offset int) (*uds.GoodsOrderList, *resty.Response, error) {
+			pages++
+			end := min(offset+maxValue, len(orders))
+			return &uds.GoodsOrderList{Rows: orders[offset:end], Total: len(orders)}, nil, nil
+		},
+	}
+
+	poller := uds.NewGoodsOrderPoller(mock)
+	ctx := context.Background()
+
+	poll := func() []uds.GoodsOrderEvent {
+		t.Helper()
+		pages = 0
+		events, err := poller.Poll(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return events
+	}
+
+	if events := poll(); len(events) != 0 || pages != 3 {
+		t.Fatalf("first poll: %d events, %d pages, want 0 events and the whole list in 3 pages", len(events), pages)
+	}
+
+	// Самый старый отслеживаемый заказ (101) находится на первой странице.
+	if events := poll(); len(events) != 0 || pages != 1 {
+		t.Fatalf("second poll: %d events, %d pages, want 0 events and 1 page", len(events), pages)
+	}
+
+	orders[0].State = uds.GoodsOrderStateCompleted
+	events := poll()
+	if len(events) != 1 {
+		t.Fatalf("third poll: %d events, want 1", len(events))
+	}
+	if changed, ok := events[0].(uds.OrderStateChanged); !ok || changed.Order.Id != 120 || changed.Previous != uds.GoodsOrderStateNew {
+		t.Fatalf("third poll: event %#v, want OrderStateChanged for order 120", events[0])
+	}
+
+	// Выполненный заказ больше не отслеживается и не выдает событий.
+	if events := poll(); len(events) != 0 {
+		t.Fatalf("fourth poll: %d events, want 0", len(events))
+	}
+
+	orders = append([]uds.GoodsOrderDetailed{{Id: 121, State: uds.GoodsOrderStateCompleted, DateCreated: base.Add(121 * time.Minute)}}, orders...)
+	events = poll()
+	if len(events) != 1 {
+		t.Fatalf("fifth poll: %d events, want 1", len(events))
+	}
+	if created, ok := events[0].(uds.OrderCreated); !ok || created.Order.Id != 121 {
+		t.Fatalf("fifth poll: event %#v, want OrderCreated for order 121", events[0])
+	}
+	if events := poll(); len(events) != 0 {
+		t.Fatalf("sixth poll: %d events, want 0", len(events))
+	}
+}
@@ -0,0 +1,176 @@
+package uds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-resty/resty/v2"
+	"slices"
+	"strconv"
+)
+
+// ErrGoodsOrderTransition
+// Переход заказа в запрошенный статус из текущего статуса не допускается.
+// Возвращается до обращения к API, подробности в GoodsOrderTransitionError.
+var ErrGoodsOrderTransition = errors.New("uds: goods order state transition not allowed")
+
+// GoodsOrderTransitionError
+// Недопустимый переход заказа между статусами. errors.Is(err, ErrGoodsOrderTransition) возвращает true.
+type GoodsOrderTransitionError struct {
+	OrderID int64           // ID заказа.
+	From    GoodsOrderState // Текущий статус заказа, пустой если он неизвестен.
+	To      GoodsOrderState // Запрошенный статус заказа.
+}
+
+func (e *GoodsOrderTransitionError) Error() string {
+	if e.From == "" {
+		return fmt.Sprintf("uds: goods order %d: state %s can not be set directly", e.OrderID, e.To)
+	}
+	return fmt.Sprintf("uds: goods order %d: transition from %s to %s not allowed", e.OrderID, e.From, e.To)
+}
+
+func (e *GoodsOrderTransitionError) Unwrap() error {
+	return ErrGoodsOrderTransition
+}
+
+// goodsOrderTransitions
+// Допустимые переходы между статусами заказа. Завершенный (COMPLETED)
+// и отмененный (DELETED) заказы больше не меняют статус.
+// В COMPLETED заказ переводит только GoodsOrderComplete, в DELETED - только GoodsOrderCancel
+// (отдельного метода удаления заказа в API нет), в NEW и WAITING_PAYMENT - GoodsOrderSetState.
+var goodsOrderTransitions = map[GoodsOrderState][]GoodsOrderState{
+	GoodsOrderStateNew:            {GoodsOrderStateWaitingPayment, GoodsOrderStateCompleted, GoodsOrderStateDeleted},
+	GoodsOrderStateWaitingPayment: {GoodsOrderStateNew, GoodsOrderStateCompleted, GoodsOrderStateDeleted},
+}
+
+// CanTransitionTo
+// Допускается ли переход заказа из статуса s в статус to.
+func (s GoodsOrderState) CanTransitionTo(to GoodsOrderState) bool {
+	return slices.Contains(goodsOrderTransitions[s], to)
+}
+
+// Final
+// Статус, из которого заказ не может перейти в другой статус (COMPLETED, DELETED).
+func (s GoodsOrderState) Final() bool {
+	return len(goodsOrderTransitions[s]) == 0
+}
+
+// ValidateGoodsOrderTransition
+// Проверяет, что заказ order может перейти в статус to.
+// Возвращает *GoodsOrderTransitionError, если переход не допускается.
+func ValidateGoodsOrderTransition(order *GoodsOrderDetailed, to GoodsOrderState) error {
+	if order == nil {
+		return errors.New("uds: goods order is nil")
+	}
+
+	if !order.State.CanTransitionTo(to) {
+		return &GoodsOrderTransitionError{OrderID: int64(order.Id), From: order.State, To: to}
+	}
+	return nil
+}
+
+// validateGoodsOrderSetState
+// Проверяет, что статус to можно установить через GoodsOrderSetState.
+func validateGoodsOrderSetState(id int64, to GoodsOrderState) error {
+	if to != GoodsOrderStateNew && to != GoodsOrderStateWaitingPayment {
+		return &GoodsOrderTransitionError{OrderID: id, To: to}
+	}
+	return nil
+}
+
+// TransitionGoodsOrder
+// Переводит заказ order в статус to, проверив допустимость перехода до обращения к API:
+// COMPLETED - через GoodsOrderComplete, DELETED - через GoodsOrderCancel с причиной reason,
+// NEW и WAITING_PAYMENT - через GoodsOrderSetState.
+// Статус берется из order без дополнительного запроса, поэтому order должен быть актуальным:
+// методы клиента сами текущий статус заказа не проверяют.
+func TransitionGoodsOrder(ctx context.Context, orders GoodsOrdersAPI, order *GoodsOrderDetailed, to GoodsOrderState, reason string) (*GoodsOrderDetailed, error) {
+	if err := ValidateGoodsOrderTransition(order, to); err != nil {
+		return nil, err
+	}
+
+	id := int64(order.Id)
+
+	switch to {
+	case GoodsOrderStateCompleted:
+		completed, _, err := orders.GoodsOrderCompleteWithContext(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &completed.Order, nil
+	case GoodsOrderStateDeleted:
+		cancelled, _, err := orders.GoodsOrderCancelWithContext(ctx, id, reason)
+		return cancelled, err
+	default:
+		updated, _, err := orders.GoodsOrderSetStateWithContext(ctx, id, to)
+		return updated, err
+	}
+}
+
+// GoodsOrderCancel
+// Отменяет заказ с указанием причины. Отмененный заказ переходит в статус DELETED.
+// https://docs.uds.app/#tag/Goods-Order/paths/~1goods-orders~1{id}~1cancel/post
+func (u *Client) GoodsOrderCancel(id int64, reason string) (*GoodsOrderDetailed, *resty.Response, error) {
+	return u.GoodsOrderCancelWithContext(context.Background(), id, reason)
+}
+
+// GoodsOrderCancelWithContext
+// Отменяет заказ с указанием причины с учетом контекста ctx. Отмененный заказ переходит в статус DELETED.
+// https://docs.uds.app/#tag/Goods-Order/paths/~1goods-orders~1{id}~1cancel/post
+func (u *Client) GoodsOrderCancelWithContext(ctx context.Context, id int64, reason string) (*GoodsOrderDetailed, *resty.Response, error) {
+	type s struct {
+		Reason string `json:"reason,omitempty"` // Причина отмены заказа.
+	}
+	goodsOrder := new(GoodsOrderDetailed)
+
+	idString := strconv.FormatInt(id, 10)
+
+	req := u.newRequest(ctx).SetPathParam("id", idString).
+		SetBody(s{Reason: reason}).
+		SetResult(goodsOrder)
+
+	resp, err := u.execute("GoodsOrderCancel", req, resty.MethodPost, "goods-orders/{id}/cancel")
+
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return goodsOrder, resp, nil
+}
+
+// GoodsOrderSetState
+// Переводит заказ в статус NEW или WAITING_PAYMENT. Для завершения заказа используйте GoodsOrderComplete,
+// для отмены (статус DELETED) - GoodsOrderCancel, другие статусы отклоняются с ErrGoodsOrderTransition без обращения к API.
+// Текущий статус заказа не проверяется, для этого используйте TransitionGoodsOrder.
+// https://docs.uds.app/#tag/Goods-Order/paths/~1goods-orders~1{id}~1state/put
+func (u *Client) GoodsOrderSetState(id int64, state GoodsOrderState) (*GoodsOrderDetailed, *resty.Response, error) {
+	return u.GoodsOrderSetStateWithContext(context.Background(), id, state)
+}
+
+// GoodsOrderSetStateWithContext
+// Переводит заказ в статус NEW или WAITING_PAYMENT с учетом контекста ctx.
+// https://docs.uds.app/#tag/Goods-Order/paths/~1goods-orders~1{id}~1state/put
+func (u *Client) GoodsOrderSetStateWithContext(ctx context.Context, id int64, state GoodsOrderState) (*GoodsOrderDetailed, *resty.Response, error) {
+	if err := validateGoodsOrderSetState(id, state); err != nil {
+		return nil, nil, err
+	}
+
+	type s struct {
+		State GoodsOrderState `json:"state"` // Новый статус заказа.
+	}
+	goodsOrder := new(GoodsOrderDetailed)
+
+	idString := strconv.FormatInt(id, 10)
+
+	req := u.newRequest(ctx).SetPathParam("id", idString).
+		SetBody(s{State: state}).
+		SetResult(goodsOrder)
+
+	resp, err := u.execute("GoodsOrderSetState", req, resty.MethodPut, "goods-orders/{id}/state")
+
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return goodsOrder, resp, nil
+}
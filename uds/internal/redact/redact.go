@@ -0,0 +1,143 @@
+// Package redact содержит общий для логов клиента и кассет udscassette список
+// секретных полей и функции их маскирования.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Mask
+// Значение, которым заменяются секреты.
+const Mask = "***"
+
+// Keys
+// Параметры запроса и поля JSON с кодами на оплату и номерами телефонов клиентов.
+var Keys = map[string]bool{
+	"code":          true,
+	"phone":         true,
+	"receiverPhone": true,
+}
+
+// Headers
+// Заголовки с данными аутентификации.
+var Headers = map[string]bool{
+	"Authorization": true,
+	"Set-Cookie":    true,
+}
+
+// paramPattern
+// Параметры из Keys в URL внутри текста, например в тексте сетевой ошибки.
+var paramPattern = func() *regexp.Regexp {
+	names := make([]string, 0, len(Keys))
+	for key := range Keys {
+		names = append(names, regexp.QuoteMeta(key))
+	}
+	slices.Sort(names)
+	return regexp.MustCompile(`\b(` + strings.Join(names, "|") + `)=[^&\s"']*`)
+}()
+
+// Query
+// Копия параметров запроса с замаскированными значениями параметров из keys.
+func Query(query url.Values, keys map[string]bool) url.Values {
+	masked := url.Values{}
+	for key, values := range query {
+		if keys[key] {
+			masked.Set(key, Mask)
+			continue
+		}
+		masked[key] = slices.Clone(values)
+	}
+	return masked
+}
+
+// Params
+// Текст с замаскированными значениями параметров из Keys, записанных как key=value.
+func Params(text string) string {
+	return paramPattern.ReplaceAllString(text, "${1}="+Mask)
+}
+
+// Body
+// JSON с замаскированными значениями полей из keys на любом уровне вложенности,
+// приведенный к каноническому виду с отсортированными ключами.
+// Тело, не являющееся JSON, возвращается без изменений.
+func Body(body []byte, keys map[string]bool) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	value, ok := decode(body)
+	if !ok {
+		return string(body)
+	}
+
+	masked, err := json.Marshal(Value(value, keys))
+	if err != nil {
+		return string(body)
+	}
+
+	return string(masked)
+}
+
+// Value
+// Маскирует значения полей из keys в разобранном JSON value на любом уровне вложенности.
+func Value(value any, keys map[string]bool) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if keys[key] && item != nil {
+				v[key] = Mask
+				continue
+			}
+			v[key] = Value(item, keys)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = Value(item, keys)
+		}
+	}
+	return value
+}
+
+// Values
+// Непустые значения полей из Keys в JSON body на любом уровне вложенности.
+func Values(body []byte) []string {
+	value, ok := decode(body)
+	if !ok {
+		return nil
+	}
+	return slices.DeleteFunc(collect(nil, value), func(value string) bool { return value == "" })
+}
+
+func collect(values []string, value any) []string {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if Keys[key] && item != nil {
+				values = append(values, fmt.Sprint(item))
+				continue
+			}
+			values = collect(values, item)
+		}
+	case []any:
+		for _, item := range v {
+			values = collect(values, item)
+		}
+	}
+	return values
+}
+
+func decode(body []byte) (any, bool) {
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
@@ -0,0 +1,180 @@
+package uds
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/arcsub/go-uds/uds/internal/redact"
+	"github.com/go-resty/resty/v2"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// logAttempt
+// Записывает в лог результат попытки запроса.
+func (u *Client) logAttempt(ctx context.Context, req *resty.Request, attempt RetryAttempt, latency time.Duration) {
+	if u.logger == nil {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("operation", attempt.Operation),
+		slog.String("method", attempt.Method),
+		slog.String("path", attempt.URL),
+		slog.String("requestId", attempt.RequestID),
+		slog.Int("attempt", attempt.Attempt),
+		slog.Duration("latency", latency),
+	}
+
+	if attempt.Response != nil && attempt.Response.RawResponse != nil {
+		attrs = append(attrs,
+			slog.String("url", redactURL(attempt.Response.RawResponse.Request.URL)),
+			slog.Int("status", attempt.Response.StatusCode()),
+		)
+	}
+
+	level := slog.LevelInfo
+	msg := "uds request"
+
+	if attempt.Err != nil {
+		var apiErr *ApiError
+		if errors.As(attempt.Err, &apiErr) {
+			attrs = append(attrs, slog.String("errorCode", string(apiErr.ErrorCode)))
+		}
+		attrs = append(attrs, slog.String("error", redactError(attempt.Err, req)))
+
+		level = slog.LevelError
+		msg = "uds request failed"
+
+		if attempt.Retry {
+			level = slog.LevelWarn
+			msg = "uds request failed, retrying"
+			attrs = append(attrs, slog.Duration("retryDelay", attempt.Delay))
+		}
+	}
+
+	u.logger.LogAttrs(ctx, level, msg, attrs...)
+
+	if u.debug {
+		u.logBodies(ctx, req, attempt)
+	}
+}
+
+// logBodies
+// Записывает в лог заголовки и тела запроса и ответа с маскированием секретов.
+func (u *Client) logBodies(ctx context.Context, req *resty.Request, attempt RetryAttempt) {
+	attrs := []slog.Attr{
+		slog.String("operation", attempt.Operation),
+		slog.String("method", attempt.Method),
+		slog.String("path", attempt.URL),
+		slog.String("requestId", attempt.RequestID),
+		slog.Int("attempt", attempt.Attempt),
+		slog.Any("requestHeaders", redactHeaders(req.Header)),
+	}
+
+	if req.Body != nil {
+		body, err := json.Marshal(req.Body)
+		if err == nil {
+			attrs = append(attrs, slog.String("requestBody", redactBody(body)))
+		}
+	}
+
+	if attempt.Response != nil && attempt.Response.RawResponse != nil {
+		attrs = append(attrs,
+			slog.Any("responseHeaders", redactHeaders(attempt.Response.Header())),
+			slog.String("responseBody", redactBody(attempt.Response.Body())),
+		)
+	}
+
+	u.logger.LogAttrs(ctx, slog.LevelDebug, "uds request dump", attrs...)
+}
+
+// redactURL
+// URL запроса с замаскированными кодами на оплату и номерами телефонов.
+func redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+
+	masked := *u
+	masked.User = nil
+	masked.RawQuery = strings.ReplaceAll(redact.Query(u.Query(), redact.Keys).Encode(), url.QueryEscape(redact.Mask), redact.Mask)
+	return masked.String()
+}
+
+// redactError
+// Текст ошибки с замаскированными кодами на оплату и номерами телефонов.
+// Сетевые ошибки содержат URL запроса, а ошибки API могут повторять значения полей запроса,
+// поэтому маскируются и параметры в URL, и все секретные значения, переданные в запросе.
+func redactError(err error, req *resty.Request) string {
+	text := redact.Params(err.Error())
+
+	for _, value := range redactedValues(req) {
+		text = strings.ReplaceAll(text, value, redact.Mask)
+		text = strings.ReplaceAll(text, url.QueryEscape(value), redact.Mask)
+	}
+
+	return text
+}
+
+// redactedValues
+// Секретные значения параметров и полей тела запроса.
+func redactedValues(req *resty.Request) []string {
+	var values []string
+
+	for key, params := range req.QueryParam {
+		if redact.Keys[key] {
+			values = append(values, params...)
+		}
+	}
+
+	if req.Body != nil {
+		if body, err := json.Marshal(req.Body); err == nil {
+			values = append(values, redact.Values(body)...)
+		}
+	}
+
+	return slices.DeleteFunc(values, func(value string) bool { return value == "" })
+}
+
+// redactHeaders
+// Копия заголовков с замаскированными данными аутентификации.
+func redactHeaders(header http.Header) http.Header {
+	masked := header.Clone()
+	for key := range masked {
+		if redact.Headers[http.CanonicalHeaderKey(key)] {
+			masked.Set(key, redact.Mask)
+		}
+	}
+	return masked
+}
+
+// redactBody
+// Тело запроса или ответа с замаскированными кодами на оплату и номерами телефонов.
+// Тело, не являющееся JSON, возвращается без изменений.
+func redactBody(body []byte) string {
+	return redact.Body(body, redact.Keys)
+}
+
+// restyLogger
+// Перенаправляет сообщения resty в slog.
+type restyLogger struct {
+	logger *slog.Logger
+}
+
+func (l restyLogger) Errorf(format string, v ...any) {
+	l.logger.Error(fmt.Sprintf(format, v...))
+}
+
+func (l restyLogger) Warnf(format string, v ...any) {
+	l.logger.Warn(fmt.Sprintf(format, v...))
+}
+
+func (l restyLogger) Debugf(format string, v ...any) {
+	l.logger.Debug(fmt.Sprintf(format, v...))
+}
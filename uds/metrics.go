@@ -0,0 +1,45 @@
+package uds
+
+import (
+	"errors"
+	"github.com/go-resty/resty/v2"
+	"time"
+)
+
+// Metrics
+// Получатель метрик запросов клиента. Все методы получают логическое имя операции —
+// имя метода клиента без суффикса WithContext (например, OperationCreate или CustomerFindByCode).
+// Реализация должна быть безопасна для вызова из нескольких горутин.
+// Адаптер в текстовом формате Prometheus находится в пакете udsprom.
+type Metrics interface {
+	// RequestStarted вызывается перед первой попыткой запроса.
+	RequestStarted(operation string)
+	// RequestFinished вызывается после завершения запроса со всеми повторами.
+	// status равен 0, если ответ не был получен, code пуст, если API не вернуло ошибку.
+	RequestFinished(operation string, status int, code ErrorCode, duration time.Duration)
+	// RequestRetried вызывается перед каждой повторной попыткой запроса.
+	RequestRetried(operation string, attempt int)
+	// LimiterWaited вызывается после ожидания ограничителя частоты запросов.
+	LimiterWaited(operation string, wait time.Duration)
+}
+
+// requestFinished
+// Передает в Metrics итог запроса.
+func (u *Client) requestFinished(operation string, resp *resty.Response, err error, duration time.Duration) {
+	if u.metrics == nil {
+		return
+	}
+
+	var status int
+	if resp != nil && resp.RawResponse != nil {
+		status = resp.StatusCode()
+	}
+
+	var code ErrorCode
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) {
+		code = apiErr.ErrorCode
+	}
+
+	u.metrics.RequestFinished(operation, status, code, duration)
+}
@@ -0,0 +1,64 @@
+package uds
+
+import (
+	"slices"
+	"time"
+)
+
+// OperationFilter
+// Критерии отбора операций. API (GET /operations) принимает только max и cursor
+// и не фильтрует операции на стороне сервера, поэтому критерии применяются на стороне клиента
+// к каждой полученной операции: см. WithOperationFilter и OperationFilter.Filter.
+// Незаполненные поля не ограничивают выборку, заполненные объединяются по И,
+// значения внутри одного поля - по ИЛИ.
+type OperationFilter struct {
+	From        time.Time     // Дата операции не раньше From (включительно).
+	To          time.Time     // Дата операции раньше To (не включительно).
+	BranchIDs   []int64       // ID филиалов (Branch.Id).
+	CashierIDs  []int64       // ID сотрудников (Cashier.Id).
+	States      []ActionState // Статусы операции.
+	Actions     []string      // Типы операции.
+	CustomerIDs []int64       // ID клиентов в компании (Customer.Id).
+}
+
+// Match
+// Удовлетворяет ли операция всем критериям фильтра.
+func (f OperationFilter) Match(operation Operation) bool {
+	if !f.From.IsZero() && operation.DateCreated.Before(f.From) {
+		return false
+	}
+
+	if !f.To.IsZero() && !operation.DateCreated.Before(f.To) {
+		return false
+	}
+
+	return matchAny(f.BranchIDs, operation.Branch.Id) &&
+		matchAny(f.CashierIDs, operation.Cashier.Id) &&
+		matchAny(f.States, operation.State) &&
+		matchAny(f.Actions, operation.Action) &&
+		matchAny(f.CustomerIDs, operation.Customer.Id)
+}
+
+// Filter
+// Операции из operations, удовлетворяющие фильтру.
+func (f OperationFilter) Filter(operations []Operation) []Operation {
+	var filtered []Operation
+	for _, operation := range operations {
+		if f.Match(operation) {
+			filtered = append(filtered, operation)
+		}
+	}
+	return filtered
+}
+
+// Day
+// Фильтр операций за календарный день, которому принадлежит date, в часовом поясе date.
+func (f OperationFilter) Day(date time.Time) OperationFilter {
+	f.From = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	f.To = f.From.AddDate(0, 0, 1)
+	return f
+}
+
+func matchAny[T comparable](values []T, value T) bool {
+	return len(values) == 0 || slices.Contains(values, value)
+}
@@ -0,0 +1,238 @@
+package uds
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	DefaultStreamPageSize     = 50               // Размер страницы OperationStream по умолчанию.
+	DefaultStreamPollInterval = 30 * time.Second // Интервал опроса новых операций в режиме ожидания по умолчанию.
+)
+
+// OperationStream
+// Последовательное чтение списка операций с переходом по курсорам.
+// В режиме ожидания (WithTailPolling) после чтения всех операций поток
+// периодически запрашивает новые, пока не будет отменен ctx.
+//
+// Курсор сохраняется в CheckpointStore после того, как прочитаны все операции страницы,
+// поэтому после перезапуска чтение продолжается с первой страницы, которая не была прочитана полностью.
+// Операции такой страницы могут быть получены повторно.
+//
+//	stream := uds.NewOperationStream(client, uds.WithCheckpointStore(uds.NewFileCheckpointStore("ledger.cursor")))
+//	for stream.Next(ctx) {
+//		operation := stream.Operation()
+//		...
+//	}
+//	if err := stream.Err(); err != nil { ... }
+type OperationStream struct {
+	operations   OperationsAPI
+	checkpoint   CheckpointStore
+	pageSize     int
+	tail         bool
+	pollInterval time.Duration
+	filter       *OperationFilter
+
+	loaded     bool
+	cursor     string // Курсор после последней полностью прочитанной страницы.
+	pageCursor string // Курсор после текущей страницы.
+	page       []Operation
+	caughtUp   bool
+	current    Operation
+	err        error
+}
+
+// OperationStreamOption
+// Функциональная опция для NewOperationStream
+type OperationStreamOption func(*OperationStream)
+
+// WithStreamPageSize
+// Количество операций, запрашиваемых за один запрос (от 1 до 50).
+// По умолчанию DefaultStreamPageSize.
+func WithStreamPageSize(pageSize int) OperationStreamOption {
+	return func(s *OperationStream) {
+		s.pageSize = max(1, min(DefaultStreamPageSize, pageSize))
+	}
+}
+
+// WithStreamCursor
+// Курсор, с которого начинается чтение, если в CheckpointStore курсор не сохранен.
+func WithStreamCursor(cursor string) OperationStreamOption {
+	return func(s *OperationStream) {
+		s.cursor = cursor
+	}
+}
+
+// WithCheckpointStore
+// Хранилище, из которого загружается начальный курсор и в которое сохраняется курсор прочитанных страниц.
+func WithCheckpointStore(checkpoint CheckpointStore) OperationStreamOption {
+	return func(s *OperationStream) {
+		s.checkpoint = checkpoint
+	}
+}
+
+// WithTailPolling
+// Режим ожидания: после чтения всех операций запрашивать новые каждые interval.
+// Значение interval <= 0 означает DefaultStreamPollInterval.
+func WithTailPolling(interval time.Duration) OperationStreamOption {
+	return func(s *OperationStream) {
+		if interval <= 0 {
+			interval = DefaultStreamPollInterval
+		}
+		s.tail = true
+		s.pollInterval = interval
+	}
+}
+
+// WithOperationFilter
+// Пропускать операции, не удовлетворяющие фильтру. Фильтр применяется на стороне клиента,
+// курсор при этом сохраняется так же, как и без фильтра.
+func WithOperationFilter(filter OperationFilter) OperationStreamOption {
+	return func(s *OperationStream) {
+		s.filter = &filter
+	}
+}
+
+// NewOperationStream
+// Создает поток операций.
+func NewOperationStream(operations OperationsAPI, opts ...OperationStreamOption) *OperationStream {
+	s := &OperationStream{
+		operations:   operations,
+		pageSize:     DefaultStreamPageSize,
+		pollInterval: DefaultStreamPollInterval,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Next
+// Переходит к следующей операции (удовлетворяющей фильтру, если он задан),
+// при необходимости запрашивая следующую страницу.
+// Возвращает false, когда операции закончились (без режима ожидания),
+// поток прерван отменой ctx или произошла ошибка.
+func (s *OperationStream) Next(ctx context.Context) bool {
+	if s.err != nil {
+		return false
+	}
+
+	if !s.loaded {
+		if err := s.load(ctx); err != nil {
+			s.err = err
+			return false
+		}
+	}
+
+	for {
+		for len(s.page) == 0 {
+			if !s.fetch(ctx) {
+				return false
+			}
+		}
+
+		s.current = s.page[0]
+		s.page = s.page[1:]
+
+		if s.filter == nil || s.filter.Match(s.current) {
+			return true
+		}
+	}
+}
+
+// fetch
+// Сохраняет курсор прочитанной страницы и запрашивает следующую.
+// В режиме ожидания перед запросом выдерживает интервал опроса, если все операции уже прочитаны.
+func (s *OperationStream) fetch(ctx context.Context) bool {
+	if err := s.commit(ctx); err != nil {
+		s.err = err
+		return false
+	}
+
+	if err := ctx.Err(); err != nil {
+		s.err = err
+		return false
+	}
+
+	if s.caughtUp {
+		if !s.tail {
+			return false
+		}
+
+		if err := sleep(ctx, s.pollInterval); err != nil {
+			s.err = err
+			return false
+		}
+	}
+
+	list, _, err := s.operations.OperationGetListWithContext(ctx, s.pageSize, s.cursor)
+	if err != nil {
+		s.err = err
+		return false
+	}
+
+	s.page = list.Rows
+	s.pageCursor = s.cursor
+	if list.Cursor != "" {
+		s.pageCursor = list.Cursor
+	}
+	s.caughtUp = len(list.Rows) < s.pageSize
+	return true
+}
+
+// Operation
+// Текущая операция после успешного вызова Next.
+func (s *OperationStream) Operation() Operation {
+	return s.current
+}
+
+// Err
+// Ошибка, остановившая поток, или nil, если операции просто закончились.
+func (s *OperationStream) Err() error {
+	return s.err
+}
+
+// Cursor
+// Курсор после последней полностью прочитанной страницы.
+func (s *OperationStream) Cursor() string {
+	return s.cursor
+}
+
+// load
+// Загружает начальный курсор из CheckpointStore.
+func (s *OperationStream) load(ctx context.Context) error {
+	s.loaded = true
+
+	if s.checkpoint != nil {
+		cursor, err := s.checkpoint.Load(ctx)
+		if err != nil {
+			return err
+		}
+
+		if cursor != "" {
+			s.cursor = cursor
+		}
+	}
+
+	s.pageCursor = s.cursor
+	return nil
+}
+
+// commit
+// Сохраняет курсор полностью прочитанной страницы.
+func (s *OperationStream) commit(ctx context.Context) error {
+	if s.pageCursor == s.cursor {
+		return nil
+	}
+
+	if s.checkpoint != nil {
+		if err := s.checkpoint.Save(ctx, s.pageCursor); err != nil {
+			return err
+		}
+	}
+
+	s.cursor = s.pageCursor
+	return nil
+}
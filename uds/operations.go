@@ -1,6 +1,7 @@
 package uds
 
 import (
+	"context"
 	"github.com/go-resty/resty/v2"
 	"github.com/google/uuid"
 	"strconv"
@@ -85,10 +86,18 @@ type CashierExternal struct {
 // Получить Список операций
 // https://docs.uds.app/#tag/Operations/paths/~1operations/get
 func (u *Client) OperationGetList(maxValue int, cursor string) (*OperationList, *resty.Response, error) {
+	return u.OperationGetListWithContext(context.Background(), maxValue, cursor)
+}
+
+// OperationGetListWithContext
+// Получить Список операций с учетом контекста ctx
+// API не поддерживает фильтрацию операций, для отбора по дате, филиалу, сотруднику,
+// статусу, типу операции или клиенту используйте OperationFilter.
+// https://docs.uds.app/#tag/Operations/paths/~1operations/get
+func (u *Client) OperationGetListWithContext(ctx context.Context, maxValue int, cursor string) (*OperationList, *resty.Response, error) {
 	operationList := new(OperationList)
-	apiErr := new(ApiError)
 
-	req := u.client.R()
+	req := u.newRequest(ctx)
 
 	if maxValue > 0 {
 		maxValue = max(1, min(50, maxValue)) // от 1 до 50
@@ -100,19 +109,12 @@ func (u *Client) OperationGetList(maxValue int, cursor string) (*OperationList,
 		req.SetQueryParam("cursor", cursor)
 	}
 
-	resp, err := req.
-		SetResult(operationList).
-		SetError(apiErr).
-		Get("operations")
+	resp, err := u.execute("OperationGetList", req.SetResult(operationList), resty.MethodGet, "operations")
 
 	if err != nil {
 		return nil, resp, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp, apiErr
-	}
-
 	return operationList, resp, nil
 }
 
@@ -164,16 +166,25 @@ type CreateOperationResponse struct {
 // Проведение операции
 // https://docs.uds.app/#tag/Operations/paths/~1operations/post
 func (u *Client) OperationCreate(operation *CreateOperationRequest) (*CreateOperationResponse, *resty.Response, error) {
+	return u.OperationCreateWithContext(context.Background(), operation)
+}
+
+// OperationCreateWithContext
+// Проведение операции с учетом контекста ctx
+// https://docs.uds.app/#tag/Operations/paths/~1operations/post
+func (u *Client) OperationCreateWithContext(ctx context.Context, operation *CreateOperationRequest) (*CreateOperationResponse, *resty.Response, error) {
 	createResp := new(CreateOperationResponse)
 
 	if operation.Nonce == "" {
 		operation.Nonce = uuid.New().String()
 	}
 
-	resp, err := u.client.R().
+	req := u.newRequest(ctx).
 		SetBody(operation).
-		SetResult(createResp).
-		Post("operations")
+		SetResult(createResp)
+
+	// Повтор безопасен: операция с тем же Nonce не будет проведена повторно
+	resp, err := u.executeIdempotent("OperationCreate", req, resty.MethodPost, "operations")
 
 	if err != nil {
 		return nil, resp, err
@@ -186,13 +197,21 @@ func (u *Client) OperationCreate(operation *CreateOperationRequest) (*CreateOper
 // Получение информации об операции
 // https://docs.uds.app/#tag/Operations/paths/~1operations~1{id}/get
 func (u *Client) OperationGetByID(id int64) (*Operation, *resty.Response, error) {
+	return u.OperationGetByIDWithContext(context.Background(), id)
+}
+
+// OperationGetByIDWithContext
+// Получение информации об операции с учетом контекста ctx
+// https://docs.uds.app/#tag/Operations/paths/~1operations~1{id}/get
+func (u *Client) OperationGetByIDWithContext(ctx context.Context, id int64) (*Operation, *resty.Response, error) {
 	operation := new(Operation)
 
 	idString := strconv.FormatInt(id, 10)
 
-	resp, err := u.client.R().SetPathParam("id", idString).
-		SetResult(operation).
-		Get("operations/{id}")
+	req := u.newRequest(ctx).SetPathParam("id", idString).
+		SetResult(operation)
+
+	resp, err := u.execute("OperationGetByID", req, resty.MethodGet, "operations/{id}")
 
 	if err != nil {
 		return nil, resp, err
@@ -211,15 +230,23 @@ type RefundOperationRequest struct {
 // Операция возврата
 // https://docs.uds.app/#tag/Operations/paths/~1operations~1{id}~1refund/post
 func (u *Client) OperationRefund(id int64, partialAmount float64) (*Operation, *resty.Response, error) {
+	return u.OperationRefundWithContext(context.Background(), id, partialAmount)
+}
+
+// OperationRefundWithContext
+// Операция возврата с учетом контекста ctx
+// https://docs.uds.app/#tag/Operations/paths/~1operations~1{id}~1refund/post
+func (u *Client) OperationRefundWithContext(ctx context.Context, id int64, partialAmount float64) (*Operation, *resty.Response, error) {
 	operation := new(Operation)
 
 	idString := strconv.FormatInt(id, 10)
 	refund := RefundOperationRequest{partialAmount}
 
-	resp, err := u.client.R().SetPathParam("id", idString).
+	req := u.newRequest(ctx).SetPathParam("id", idString).
 		SetBody(refund).
-		SetResult(operation).
-		Post("operations/{id}/refund")
+		SetResult(operation)
+
+	resp, err := u.execute("OperationRefund", req, resty.MethodPost, "operations/{id}/refund")
 
 	if err != nil {
 		return nil, resp, err
@@ -274,12 +301,20 @@ type CalcOperationResponse struct {
 // Рассчитать информацию по операции
 // https://docs.uds.app/#tag/Operations/paths/~1operations~1calc/post
 func (u *Client) OperationCalc(operation *CalcOperationRequest) (*CalcOperationResponse, *resty.Response, error) {
+	return u.OperationCalcWithContext(context.Background(), operation)
+}
+
+// OperationCalcWithContext
+// Рассчитать информацию по операции с учетом контекста ctx
+// https://docs.uds.app/#tag/Operations/paths/~1operations~1calc/post
+func (u *Client) OperationCalcWithContext(ctx context.Context, operation *CalcOperationRequest) (*CalcOperationResponse, *resty.Response, error) {
 	calcResp := new(CalcOperationResponse)
 
-	resp, err := u.client.R().
+	req := u.newRequest(ctx).
 		SetBody(operation).
-		SetResult(calcResp).
-		Post("operations/calc")
+		SetResult(calcResp)
+
+	resp, err := u.execute("OperationCalc", req, resty.MethodPost, "operations/calc")
 
 	if err != nil {
 		return nil, resp, err
@@ -307,22 +342,24 @@ type RewardOperationResponse struct {
 // Начисление бонусов клиенту (подарок)
 // https://docs.uds.app/#tag/Operations/paths/~1operations~1reward/post
 func (u *Client) OperationReward(operation RewardOperationRequest) (*RewardOperationResponse, *resty.Response, error) {
+	return u.OperationRewardWithContext(context.Background(), operation)
+}
+
+// OperationRewardWithContext
+// Начисление бонусов клиенту (подарок) с учетом контекста ctx
+// https://docs.uds.app/#tag/Operations/paths/~1operations~1reward/post
+func (u *Client) OperationRewardWithContext(ctx context.Context, operation RewardOperationRequest) (*RewardOperationResponse, *resty.Response, error) {
 	rewardResp := new(RewardOperationResponse)
-	apiErr := new(ApiError)
 
-	resp, err := u.client.R().
+	req := u.newRequest(ctx).
 		SetBody(operation).
-		SetResult(rewardResp).
-		SetError(apiErr).
-		Post("operations/reward")
+		SetResult(rewardResp)
+
+	resp, err := u.execute("OperationReward", req, resty.MethodPost, "operations/reward")
 
 	if err != nil {
 		return nil, resp, err
 	}
 
-	if resp.Error() != nil {
-		return nil, resp, apiErr
-	}
-
 	return rewardResp, resp, nil
 }
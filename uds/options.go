@@ -0,0 +1,156 @@
+package uds
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	LanguageRussian = "ru-RU, ru" // Язык ответов API по умолчанию
+	LanguageEnglish = "en-US, en" // Английский язык ответов API
+)
+
+// options
+// Параметры создания клиента UDS
+type options struct {
+	baseURL    string
+	httpClient *http.Client
+	timeout    time.Duration
+	language   string
+	userAgent  string
+
+	retryPolicy RetryPolicy
+
+	rateLimiter   *RateLimiter
+	rateLimit     float64
+	rateBurst     int
+	onLimiterWait func(wait time.Duration)
+
+	logger *slog.Logger
+	debug  bool
+
+	metrics Metrics
+}
+
+func defaultOptions() *options {
+	return &options{
+		baseURL:  BaseUri,
+		language: LanguageRussian,
+
+		retryPolicy: DefaultRetryPolicy(),
+	}
+}
+
+// Option
+// Функциональная опция для NewClient
+type Option func(*options)
+
+// WithBaseURL
+// Адрес API, к которому обращается клиент (например, тестовый стенд или локальный фейк).
+// По умолчанию BaseUri.
+func WithBaseURL(baseURL string) Option {
+	return func(o *options) {
+		o.baseURL = baseURL
+	}
+}
+
+// WithHTTPClient
+// HTTP клиент, через который выполняются запросы (прокси, mTLS, собственный транспорт).
+// Клиент копируется и не изменяется при применении остальных опций.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithTimeout
+// Таймаут одного запроса к API, включая чтение ответа.
+// Ноль означает отсутствие таймаута.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithLanguage
+// Значение заголовка Accept-Language, определяющее язык сообщений об ошибках.
+// По умолчанию LanguageRussian.
+func WithLanguage(language string) Option {
+	return func(o *options) {
+		o.language = language
+	}
+}
+
+// WithUserAgent
+// Значение заголовка User-Agent, идентифицирующее интеграцию.
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithRetryPolicy
+// Политика повторных запросов. По умолчанию DefaultRetryPolicy,
+// отключить повторы можно с помощью NoRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retryPolicy = policy
+	}
+}
+
+// WithRateLimiter
+// Ограничитель частоты запросов. Один ограничитель можно передать нескольким клиентам,
+// чтобы они разделяли общую квоту.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(o *options) {
+		o.rateLimiter = limiter
+	}
+}
+
+// WithRateLimit
+// Ограничение частоты запросов rps в секунду с всплеском burst.
+// Ограничитель общий для всех клиентов с тем же ID компании (см. SharedRateLimiter).
+// Если для компании уже создан ограничитель с другими параметрами, каждый запрос клиента
+// возвращает ErrRateLimiterConflict.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(o *options) {
+		o.rateLimit = rps
+		o.rateBurst = burst
+	}
+}
+
+// WithLimiterWaitHook
+// Функция, получающая время ожидания ограничителя частоты перед каждым запросом,
+// например, для отправки в систему метрик.
+func WithLimiterWaitHook(hook func(wait time.Duration)) Option {
+	return func(o *options) {
+		o.onLimiterWait = hook
+	}
+}
+
+// WithLogger
+// Логгер запросов: метод, путь, статус, время выполнения, попытки и коды ошибок UDS.
+// Данные аутентификации, коды на оплату и номера телефонов маскируются.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithDebug
+// Режим отладки: дополнительно записывает в лог на уровне Debug заголовки и тела
+// запросов и ответов с тем же маскированием, что и основной лог. Требует WithLogger.
+func WithDebug(debug bool) Option {
+	return func(o *options) {
+		o.debug = debug
+	}
+}
+
+// WithMetrics
+// Получатель метрик времени выполнения, кодов ошибок и повторов запросов.
+func WithMetrics(metrics Metrics) Option {
+	return func(o *options) {
+		o.metrics = metrics
+	}
+}
@@ -0,0 +1,142 @@
+package uds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimiter
+// Ограничитель частоты запросов по алгоритму token bucket.
+// Безопасен для использования из нескольких горутин и может разделяться
+// между несколькими клиентами одной компании (см. WithRateLimiter и WithRateLimit).
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64   // Скорость пополнения, токенов в секунду.
+	burst  float64   // Емкость корзины.
+	tokens float64   // Доступные токены, отрицательное значение — зарезервированные наперед.
+	last   time.Time // Время последнего пополнения.
+}
+
+// NewRateLimiter
+// Создает ограничитель на rps запросов в секунду с допустимым всплеском burst запросов.
+// Значение rps <= 0 означает отсутствие ограничения: Wait сразу возвращает управление.
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	burst = max(1, burst)
+	return &RateLimiter{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait
+// Ожидает разрешения на выполнение запроса или отмены ctx.
+// Возвращает время ожидания.
+func (l *RateLimiter) Wait(ctx context.Context) (time.Duration, error) {
+	delay, ok := l.reserve(ctx)
+	if !ok {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		return 0, context.DeadlineExceeded
+	}
+
+	if delay == 0 {
+		return 0, nil
+	}
+
+	if err := sleep(ctx, delay); err != nil {
+		l.cancel()
+		return 0, err
+	}
+
+	return delay, nil
+}
+
+// reserve
+// Резервирует токен и возвращает время, через которое его можно использовать.
+// Не резервирует токен, если ожидание не уложится в дедлайн ctx.
+func (l *RateLimiter) reserve(ctx context.Context) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if ctx.Err() != nil {
+		return 0, false
+	}
+
+	if l.rate <= 0 {
+		return 0, true
+	}
+
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	var delay time.Duration
+	if l.tokens < 1 {
+		delay = time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && now.Add(delay).After(deadline) {
+		return 0, false
+	}
+
+	l.tokens--
+	return delay, true
+}
+
+// cancel
+// Возвращает зарезервированный токен, если ожидание было прервано.
+func (l *RateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens = min(l.burst, l.tokens+1)
+}
+
+// ErrRateLimiterConflict
+// Общий ограничитель компании уже создан с другими параметрами rps и burst.
+var ErrRateLimiterConflict = errors.New("uds: shared rate limiter already exists with different parameters")
+
+var (
+	sharedLimitersMu sync.Mutex
+	sharedLimiters   = map[string]*RateLimiter{}
+)
+
+// SharedRateLimiter
+// Возвращает ограничитель, общий для всех клиентов компании companyID.
+// Ограничитель создается при первом обращении с параметрами rps и burst.
+// Последующие обращения с теми же параметрами возвращают уже созданный ограничитель,
+// с другими - ошибку ErrRateLimiterConflict.
+func SharedRateLimiter(companyID string, rps float64, burst int) (*RateLimiter, error) {
+	sharedLimitersMu.Lock()
+	defer sharedLimitersMu.Unlock()
+
+	limiter, ok := sharedLimiters[companyID]
+	if !ok {
+		limiter = NewRateLimiter(rps, burst)
+		sharedLimiters[companyID] = limiter
+		return limiter, nil
+	}
+
+	if limiter.rate != rps || limiter.burst != float64(max(1, burst)) {
+		return nil, fmt.Errorf("%w: company %s has rps %g, burst %g, requested rps %g, burst %d",
+			ErrRateLimiterConflict, companyID, limiter.rate, limiter.burst, rps, burst)
+	}
+
+	return limiter, nil
+}
+
+// ReleaseSharedRateLimiter
+// Удаляет общий ограничитель компании companyID. Клиенты, уже получившие ограничитель,
+// продолжают его использовать, а следующий SharedRateLimiter создаст новый.
+func ReleaseSharedRateLimiter(companyID string) {
+	sharedLimitersMu.Lock()
+	defer sharedLimitersMu.Unlock()
+
+	delete(sharedLimiters, companyID)
+}
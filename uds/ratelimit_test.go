@@ -0,0 +1,37 @@
+package uds_test
+
+import (
+	"errors"
+	"github.com/arcsub/go-uds/uds"
+	"testing"
+)
+
+func TestSharedRateLimiter(t *testing.T) {
+	const companyID = "shared-rate-limiter-test"
+	t.Cleanup(func() { uds.ReleaseSharedRateLimiter(companyID) })
+
+	first, err := uds.SharedRateLimiter(companyID, 10, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	same, err := uds.SharedRateLimiter(companyID, 10, 5)
+	if err != nil || same != first {
+		t.Fatalf("SharedRateLimiter with the same parameters = %p, %v, want %p", same, err, first)
+	}
+
+	if _, err = uds.SharedRateLimiter(companyID, 20, 5); !errors.Is(err, uds.ErrRateLimiterConflict) {
+		t.Fatalf("SharedRateLimiter with another rps err = %v, want ErrRateLimiterConflict", err)
+	}
+
+	client := uds.NewClient(companyID, "key", uds.WithRateLimit(10, 10))
+	if _, _, err = client.SettingsGet(); !errors.Is(err, uds.ErrRateLimiterConflict) {
+		t.Fatalf("request with a conflicting WithRateLimit err = %v, want ErrRateLimiterConflict", err)
+	}
+
+	uds.ReleaseSharedRateLimiter(companyID)
+	released, err := uds.SharedRateLimiter(companyID, 20, 5)
+	if err != nil || released == first {
+		t.Fatalf("SharedRateLimiter after release = %p, %v, want a new limiter", released, err)
+	}
+}
@@ -0,0 +1,177 @@
+package uds
+
+import "fmt"
+
+// Validate
+// Проверяет суммы чека перед OperationCreate по тем же правилам, что и API:
+// контрольную сумму (см. ErrInvalidChecksum), баланс клиента (ErrInsufficientFunds)
+// и допустимый процент оплаты баллами (ErrDiscountLimitExceed).
+// Суммы сравниваются с точностью до сотых, баллы округляются вниз.
+//
+// Возвращает nil или *ApiError с кодом ErrBadRequest, в поле Errors которого перечислены
+// все нарушения с указанием поля чека и ожидаемого значения. Код каждого нарушения
+// можно проверить через errors.Is:
+//
+//	if err := receipt.Validate(settings, &customer.Participant); errors.Is(err, uds.ErrInvalidChecksum) { ... }
+//
+// Если settings равно nil, контрольная сумма проверяется для CHARGE_SCORES, а процент оплаты баллами не проверяется.
+// Если participant равно nil, не проверяется баланс клиента, а статус берется базовый.
+// Если сумма в чеке или баланс клиента равны NaN, бесконечности или не помещаются в Money или Points,
+// возвращается ошибка преобразования, а не *ApiError.
+func (r Receipt) Validate(settings *Settings, participant *Participant) error {
+	var fieldErrors []BadRequestError
+
+	if r.Total <= 0 {
+		fieldErrors = append(fieldErrors, receiptFieldError("invalid", "receipt.total", r.Total, "must be greater than 0"))
+	}
+	if r.Cash < 0 {
+		fieldErrors = append(fieldErrors, receiptFieldError("invalid", "receipt.cash", r.Cash, "must not be negative"))
+	}
+	if r.Points < 0 {
+		fieldErrors = append(fieldErrors, receiptFieldError("invalid", "receipt.points", r.Points, "must not be negative"))
+	}
+	if r.SkipLoyaltyTotal != nil && (*r.SkipLoyaltyTotal < 0 || *r.SkipLoyaltyTotal > r.Total) {
+		fieldErrors = append(fieldErrors, receiptFieldError("invalid", "receipt.skipLoyaltyTotal", *r.SkipLoyaltyTotal, "must be between 0 and total"))
+	}
+	if r.UnredeemableTotal != nil && (*r.UnredeemableTotal < 0 || *r.UnredeemableTotal > r.Total) {
+		fieldErrors = append(fieldErrors, receiptFieldError("invalid", "receipt.unredeemableTotal", *r.UnredeemableTotal, "must be between 0 and total"))
+	}
+	if points, err := PointsFromFloat(r.Points); err == nil && points.Float64() != r.Points {
+		fieldErrors = append(fieldErrors, receiptFieldError("invalid", "receipt.points", r.Points,
+			fmt.Sprintf("must be rounded down to hundredths: %s", points)))
+	}
+
+	if len(fieldErrors) > 0 {
+		return receiptValidationError(fieldErrors)
+	}
+
+	exact, err := r.Exact()
+	if err != nil {
+		return err
+	}
+
+	var balance Points
+	if participant != nil {
+		if balance, err = PointsFromFloat(participant.Points); err != nil {
+			return fmt.Errorf("uds: participant.points: %w", err)
+		}
+	}
+
+	var tier MembershipTier
+	var policy DiscountPolicy
+	if settings != nil {
+		tier = membershipTier(settings, participant)
+		policy = settings.BaseDiscountPolicy
+	}
+
+	discountAmount, err := receiptDiscount(policy, tier, exact)
+	if err != nil {
+		return err
+	}
+
+	expectedCash := exact.Total.Sub(discountAmount).Sub(exact.Points.Money())
+	if exact.Cash.Cmp(expectedCash) != 0 {
+		message := fmt.Sprintf("must equal total - points = %s", expectedCash)
+		if !discountAmount.IsZero() {
+			message = fmt.Sprintf("must equal total - discount %s - points = %s", discountAmount, expectedCash)
+		}
+		fieldErrors = append(fieldErrors, receiptFieldError(string(ErrInvalidChecksum), "receipt.cash", r.Cash, message))
+	}
+
+	if participant != nil {
+		if exact.Points.Cmp(balance) > 0 {
+			fieldErrors = append(fieldErrors, receiptFieldError(string(ErrInsufficientFunds), "receipt.points", r.Points,
+				fmt.Sprintf("must not exceed customer balance %s", balance)))
+		}
+	}
+
+	if settings != nil {
+		limit, err := receiptPointsLimit(tier, exact, discountAmount)
+		if err != nil {
+			return err
+		}
+		if exact.Points.Cmp(limit) > 0 {
+			fieldErrors = append(fieldErrors, receiptFieldError(string(ErrDiscountLimitExceed), "receipt.points", r.Points,
+				fmt.Sprintf("must not exceed %v%% of redeemable total: %s", tier.MaxScoresDiscount, limit)))
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return receiptValidationError(fieldErrors)
+	}
+
+	return nil
+}
+
+// membershipTier
+// Статус клиента, по которому рассчитываются скидка, кешбэк и процент оплаты баллами.
+// Настройки статуса берутся из settings, если статус там найден.
+// Индивидуальный процент клиента (Participant.DiscountRate для APPLY_DISCOUNT,
+// Participant.CashbackRate для CHARGE_SCORES), если он задан, заменяет процент статуса.
+func membershipTier(settings *Settings, participant *Participant) MembershipTier {
+	tier := settings.LoyaltyProgramSettings.BaseMembershipTier
+	if participant == nil {
+		return tier
+	}
+
+	if participant.MembershipTier.Uid != "" {
+		tier = participant.MembershipTier
+		for _, t := range settings.LoyaltyProgramSettings.MembershipTiers {
+			if t.Uid == participant.MembershipTier.Uid {
+				tier = t
+				break
+			}
+		}
+	}
+
+	switch {
+	case settings.BaseDiscountPolicy == DiscountPolicyApplyDiscount && participant.DiscountRate > 0:
+		tier.Rate = participant.DiscountRate
+	case settings.BaseDiscountPolicy != DiscountPolicyApplyDiscount && participant.CashbackRate > 0:
+		tier.Rate = participant.CashbackRate
+	}
+
+	return tier
+}
+
+// receiptDiscount
+// Скидка в денежных единицах для APPLY_DISCOUNT: процент статуса от части счета,
+// участвующей в программе лояльности.
+func receiptDiscount(policy DiscountPolicy, tier MembershipTier, receipt ExactReceipt) (Money, error) {
+	if policy != DiscountPolicyApplyDiscount {
+		return Money{}, nil
+	}
+
+	loyaltyTotal := receipt.Total
+	if receipt.SkipLoyaltyTotal != nil {
+		loyaltyTotal = loyaltyTotal.Sub(*receipt.SkipLoyaltyTotal)
+	}
+	if loyaltyTotal.Sign() < 0 {
+		return Money{}, nil
+	}
+
+	return loyaltyTotal.Percent(tier.Rate, RoundHalfUp)
+}
+
+// receiptPointsLimit
+// Сколько баллов допускается списать по настройкам статуса: MaxScoresDiscount процентов
+// от части счета, которую можно погасить баллами.
+func receiptPointsLimit(tier MembershipTier, receipt ExactReceipt, discountAmount Money) (Points, error) {
+	redeemable := receipt.Total.Sub(discountAmount)
+	if receipt.UnredeemableTotal != nil {
+		redeemable = redeemable.Sub(*receipt.UnredeemableTotal)
+	}
+	if redeemable.Sign() < 0 {
+		return Points{}, nil
+	}
+
+	return redeemable.Points().Percent(tier.MaxScoresDiscount)
+}
+
+func receiptFieldError(code, field string, value any, message string) BadRequestError {
+	return BadRequestError{ErrorCode: code, Message: message, Field: field, Value: value}
+}
+
+func receiptValidationError(fieldErrors []BadRequestError) *ApiError {
+	return &ApiError{ErrorCode: ErrBadRequest, Message: "Receipt validation failed", Errors: fieldErrors}
+}
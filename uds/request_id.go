@@ -0,0 +1,66 @@
+package uds
+
+import (
+	"context"
+	"github.com/go-resty/resty/v2"
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	headerRequestID = "X-Origin-Request-Id"
+	headerTimestamp = "X-Timestamp"
+
+	timestampLayout = "2006-01-02T15:04:05.000Z07:00" // ISO-8601 с миллисекундами
+)
+
+type requestIDKey struct{}
+
+// WithRequestID
+// Возвращает контекст, запросы с которым будут отправлены с заголовком X-Origin-Request-Id = id.
+// Позволяет связать запросы к UDS с логами вызывающей стороны.
+// Без него идентификатор генерируется автоматически.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext
+// Идентификатор запроса, установленный через WithRequestID, или пустая строка.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestID
+// Идентификатор запроса (X-Origin-Request-Id), к которому относится ответ resp.
+func RequestID(resp *resty.Response) string {
+	if resp == nil {
+		return ""
+	}
+
+	if resp.Request != nil {
+		if id := resp.Request.Header.Get(headerRequestID); id != "" {
+			return id
+		}
+	}
+
+	return resp.Header().Get(headerRequestID)
+}
+
+// setRequestID
+// Устанавливает идентификатор запроса из контекста или новый UUID.
+// Идентификатор сохраняется для всех повторных попыток запроса.
+func setRequestID(req *resty.Request) {
+	id := RequestIDFromContext(req.Context())
+	if id == "" {
+		id = uuid.New().String()
+	}
+
+	req.SetHeader(headerRequestID, id)
+}
+
+// setTimestamp
+// Устанавливает время отправки попытки запроса.
+func setTimestamp(req *resty.Request) {
+	req.SetHeader(headerTimestamp, time.Now().Format(timestampLayout))
+}
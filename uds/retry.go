@@ -0,0 +1,136 @@
+package uds
+
+import (
+	"context"
+	"github.com/go-resty/resty/v2"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy
+// Политика повторных запросов.
+// Повторяются только идемпотентные запросы: GET и OperationCreate, который повторяется с тем же Nonce.
+// Запросы, перемещающие деньги без Nonce (OperationRefund, OperationReward и т.п.), не повторяются никогда.
+type RetryPolicy struct {
+	MaxAttempts     int           // Максимальное количество попыток, включая первую. 1 и меньше — без повторов.
+	InitialInterval time.Duration // Пауза перед первым повтором.
+	MaxInterval     time.Duration // Максимальная пауза между попытками.
+	Multiplier      float64       // Множитель паузы для каждой следующей попытки.
+	Jitter          float64       // Доля случайного отклонения паузы, от 0 до 1.
+	MaxElapsedTime  time.Duration // Максимальное общее время на все попытки. 0 — без ограничения.
+
+	// OnAttempt вызывается после каждой попытки, в том числе последней.
+	OnAttempt func(attempt RetryAttempt)
+}
+
+// RetryAttempt
+// Информация о выполненной попытке запроса.
+type RetryAttempt struct {
+	Operation string          // Логическое имя операции (имя метода клиента).
+	Method    string          // HTTP метод запроса.
+	URL       string          // Путь запроса.
+	Attempt   int             // Номер попытки, начиная с 1.
+	RequestID string          // Идентификатор запроса (X-Origin-Request-Id), общий для всех попыток.
+	Response  *resty.Response // Ответ API, может быть nil при сетевой ошибке.
+	Err       error           // Ошибка попытки, nil при успехе.
+	Delay     time.Duration   // Пауза перед следующей попыткой.
+	Retry     bool            // Будет ли выполнена следующая попытка.
+}
+
+// DefaultRetryPolicy
+// Политика повторов по умолчанию: до 3 попыток с экспоненциальной паузой от 200мс до 2с,
+// не дольше 5 секунд суммарно.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:     3,
+		InitialInterval: 200 * time.Millisecond,
+		MaxInterval:     2 * time.Second,
+		Multiplier:      2,
+		Jitter:          0.2,
+		MaxElapsedTime:  5 * time.Second,
+	}
+}
+
+// NoRetryPolicy
+// Политика без повторных запросов.
+func NoRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+// next
+// Определяет, нужно ли повторить запрос после попытки attempt, и паузу перед повтором.
+func (p RetryPolicy) next(attempt int, elapsed time.Duration, resp *resty.Response, err error) (time.Duration, bool) {
+	if err == nil || attempt >= p.MaxAttempts || !IsRetryable(err) {
+		return 0, false
+	}
+
+	delay := p.backoff(attempt)
+	if after, ok := retryAfter(resp); ok {
+		delay = after
+	}
+
+	if p.MaxElapsedTime > 0 && elapsed+delay > p.MaxElapsedTime {
+		return 0, false
+	}
+
+	return delay, true
+}
+
+// backoff
+// Экспоненциальная пауза со случайным отклонением после попытки attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := max(1, p.Multiplier)
+	delay := float64(p.InitialInterval) * math.Pow(multiplier, float64(attempt-1))
+
+	if p.MaxInterval > 0 {
+		delay = min(delay, float64(p.MaxInterval))
+	}
+
+	if p.Jitter > 0 {
+		jitter := min(1, p.Jitter)
+		delay += delay * jitter * (rand.Float64()*2 - 1)
+	}
+
+	return time.Duration(delay)
+}
+
+// retryAfter
+// Пауза из заголовка Retry-After для ответов 429 и 503.
+// Заголовок может содержать количество секунд или дату в формате HTTP.
+func retryAfter(resp *resty.Response) (time.Duration, bool) {
+	if resp == nil || (resp.StatusCode() != http.StatusTooManyRequests && resp.StatusCode() != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+
+	value := resp.Header().Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(0, seconds)) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(0, time.Until(date)), true
+	}
+
+	return 0, false
+}
+
+// sleep
+// Ожидает delay или отмены ctx.
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,41 @@
+package uds_test
+
+import (
+	"context"
+	"errors"
+	"github.com/arcsub/go-uds/uds"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryCancelledDuringBackoffKeepsLastError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"errorCode":"serviceUnavailable","message":"try later"}`))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := uds.NewClient("1", "key", uds.WithBaseURL(server.URL), uds.WithRetryPolicy(uds.RetryPolicy{
+		MaxAttempts:     3,
+		InitialInterval: time.Hour,
+		MaxInterval:     time.Hour,
+		Multiplier:      1,
+		OnAttempt: func(uds.RetryAttempt) {
+			cancel()
+		},
+	}))
+
+	_, _, err := client.SettingsGetWithContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+
+	var apiErr *uds.ApiError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want the last attempt's *uds.ApiError to be kept", err)
+	}
+}
@@ -1,6 +1,9 @@
 package uds
 
-import "github.com/go-resty/resty/v2"
+import (
+	"context"
+	"github.com/go-resty/resty/v2"
+)
 
 type DiscountPolicy string
 
@@ -61,9 +64,17 @@ type MembershipTier struct {
 // SettingsGet Получение настроек компании
 // https://docs.uds.app/#tag/Settings/paths/~1settings/get
 func (u *Client) SettingsGet() (*Settings, *resty.Response, error) {
+	return u.SettingsGetWithContext(context.Background())
+}
+
+// SettingsGetWithContext Получение настроек компании с учетом контекста ctx
+// https://docs.uds.app/#tag/Settings/paths/~1settings/get
+func (u *Client) SettingsGetWithContext(ctx context.Context) (*Settings, *resty.Response, error) {
 	settings := new(Settings)
 
-	resp, err := u.client.R().SetResult(settings).Get("settings")
+	req := u.newRequest(ctx).SetResult(settings)
+
+	resp, err := u.execute("SettingsGet", req, resty.MethodGet, "settings")
 	if err != nil {
 		return nil, resp, err
 	}
@@ -1,7 +1,10 @@
 package uds
 
 import (
+	"context"
+	"fmt"
 	"github.com/go-resty/resty/v2"
+	"log/slog"
 	"time"
 )
 
@@ -10,20 +13,209 @@ const BaseUri = "https://api.uds.app/partner/v2/"
 // Client
 // Структура клиента UDS
 type Client struct {
-	client *resty.Client
+	client        *resty.Client
+	retryPolicy   RetryPolicy
+	rateLimiter   *RateLimiter
+	onLimiterWait func(wait time.Duration)
+	logger        *slog.Logger
+	debug         bool
+	metrics       Metrics
+	err           error // Ошибка настройки клиента, возвращаемая каждым запросом.
 }
 
-func NewClient(clientID, apiKey string) *Client {
-	client := resty.New().SetBaseURL(BaseUri).
-		SetRetryCount(10).
-		SetRetryWaitTime(time.Second).
+// NewClient
+// Создает клиента UDS с идентификатором компании clientID и ключом apiKey.
+// Поведение по умолчанию можно изменить опциями opts.
+func NewClient(clientID, apiKey string, opts ...Option) *Client {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var client *resty.Client
+	if o.httpClient != nil {
+		httpClient := *o.httpClient
+		client = resty.NewWithClient(&httpClient)
+	} else {
+		client = resty.New()
+	}
+
+	client.SetBaseURL(o.baseURL).
 		SetHeaders(map[string]string{
 			"Accept":          "application/json",
 			"Accept-Charset":  "utf-8",
 			"Content-Type":    "application/json",
-			"Accept-Language": "ru-RU, ru",
+			"Accept-Language": o.language,
 		}).
 		SetBasicAuth(clientID, apiKey)
 
-	return &Client{client}
+	if o.timeout > 0 {
+		client.SetTimeout(o.timeout)
+	}
+
+	if o.logger != nil {
+		client.SetLogger(restyLogger{o.logger})
+	}
+
+	if o.userAgent != "" {
+		client.SetHeader("User-Agent", o.userAgent)
+	}
+
+	rateLimiter := o.rateLimiter
+	var err error
+	if rateLimiter == nil && o.rateLimit > 0 {
+		rateLimiter, err = SharedRateLimiter(clientID, o.rateLimit, o.rateBurst)
+	}
+
+	return &Client{
+		client:        client,
+		retryPolicy:   o.retryPolicy,
+		rateLimiter:   rateLimiter,
+		onLimiterWait: o.onLimiterWait,
+		logger:        o.logger,
+		debug:         o.debug,
+		metrics:       o.metrics,
+		err:           err,
+	}
+}
+
+// newRequest
+// Создает запрос к API, привязанный к контексту ctx.
+// Отмена контекста или истечение его дедлайна прерывают запрос.
+func (u *Client) newRequest(ctx context.Context) *resty.Request {
+	return u.client.R().SetContext(ctx)
+}
+
+// requestError
+// Если запрос был прерван из-за отмены или дедлайна ctx, возвращает ошибку контекста
+// (context.Canceled или context.DeadlineExceeded), чтобы её можно было отличить от ошибок API.
+func requestError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// execute
+// Единая точка выполнения запросов к API. operation — логическое имя операции для логов и метрик.
+// Ответ с кодом вне диапазона 2xx возвращается как *ApiError, если его тело удалось разобрать,
+// иначе как *HTTPError (например, HTML-страница шлюза).
+// Повторные попытки по политике RetryPolicy выполняются только для GET запросов.
+func (u *Client) execute(operation string, req *resty.Request, method, url string) (*resty.Response, error) {
+	return u.executeWithRetry(operation, req, method, url, method == resty.MethodGet)
+}
+
+// executeIdempotent
+// Выполняет запрос, который безопасно повторять по политике RetryPolicy независимо от метода
+// (например, создание операции с фиксированным Nonce).
+func (u *Client) executeIdempotent(operation string, req *resty.Request, method, url string) (*resty.Response, error) {
+	return u.executeWithRetry(operation, req, method, url, true)
+}
+
+// executeWithRetry
+// Выполняет запрос с повторами по политике RetryPolicy, если idempotent равно true.
+// При отмене ctx во время ожидания перед повтором возвращается ошибка контекста,
+// объединенная с ошибкой последней попытки.
+func (u *Client) executeWithRetry(operation string, req *resty.Request, method, url string, idempotent bool) (*resty.Response, error) {
+	if u.err != nil {
+		return nil, u.err
+	}
+
+	policy := u.retryPolicy
+	if !idempotent {
+		policy.MaxAttempts = 1
+	}
+
+	ctx := req.Context()
+	start := time.Now()
+
+	setRequestID(req)
+
+	if u.metrics != nil {
+		u.metrics.RequestStarted(operation)
+	}
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && u.metrics != nil {
+			u.metrics.RequestRetried(operation, attempt)
+		}
+
+		attemptStart := time.Now()
+		resp, err := u.attempt(operation, req, method, url)
+		latency := time.Since(attemptStart)
+
+		delay, retry := policy.next(attempt, time.Since(start), resp, err)
+
+		info := RetryAttempt{
+			Operation: operation,
+			Method:    method,
+			URL:       url,
+			Attempt:   attempt,
+			RequestID: req.Header.Get(headerRequestID),
+			Response:  resp,
+			Err:       err,
+			Delay:     delay,
+			Retry:     retry,
+		}
+
+		u.logAttempt(ctx, req, info, latency)
+
+		if policy.OnAttempt != nil {
+			policy.OnAttempt(info)
+		}
+
+		if !retry {
+			u.requestFinished(operation, resp, err, time.Since(start))
+			return resp, err
+		}
+
+		if sleepErr := sleep(ctx, delay); sleepErr != nil {
+			// Ошибка последней попытки сохраняется вместе с ошибкой контекста.
+			if err != nil {
+				sleepErr = fmt.Errorf("%w: %w", sleepErr, err)
+			}
+			u.requestFinished(operation, resp, sleepErr, time.Since(start))
+			return resp, sleepErr
+		}
+	}
+}
+
+// attempt
+// Выполняет одну попытку запроса.
+func (u *Client) attempt(operation string, req *resty.Request, method, url string) (*resty.Response, error) {
+	if u.rateLimiter != nil {
+		wait, err := u.rateLimiter.Wait(req.Context())
+		if err != nil {
+			return nil, err
+		}
+
+		if u.onLimiterWait != nil {
+			u.onLimiterWait(wait)
+		}
+
+		if u.metrics != nil {
+			u.metrics.LimiterWaited(operation, wait)
+		}
+	}
+
+	setTimestamp(req)
+
+	apiErr := new(ApiError)
+
+	resp, err := req.SetError(apiErr).Execute(method, url)
+	if err != nil {
+		return resp, requestError(req.Context(), err)
+	}
+
+	if !resp.IsSuccess() {
+		if apiErr.ErrorCode != "" {
+			apiErr.StatusCode = resp.StatusCode()
+			apiErr.RequestID = RequestID(resp)
+			apiErr.Body = resp.Body()
+			return resp, apiErr
+		}
+		return resp, newHTTPError(resp)
+	}
+
+	return resp, nil
 }
@@ -0,0 +1,340 @@
+// Package udscassette
+// Транспорт для записи реальных пар запрос/ответ UDS в файл-кассету и их воспроизведения в CI.
+// Перед сохранением из кассеты удаляются данные аутентификации, коды на оплату и номера телефонов:
+// те же поля, что маскируются в логах клиента uds.
+//
+//	recorder, err := udscassette.New("testdata/checkout.json", udscassette.ModeReplay)
+//	...
+//	defer recorder.Stop()
+//	client := uds.NewClient(clientID, apiKey, recorder.Option())
+package udscassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/arcsub/go-uds/uds"
+	"github.com/arcsub/go-uds/uds/internal/redact"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mode
+// Режим работы транспорта.
+type Mode int
+
+const (
+	ModeReplay Mode = iota // Воспроизведение кассеты, запросы в сеть не отправляются.
+	ModeRecord             // Запись запросов к реальному API в кассету.
+)
+
+// VolatileKeys
+// Поля JSON, значения которых меняются от запуска к запуску и не участвуют в сопоставлении запросов.
+var VolatileKeys = map[string]bool{
+	"nonce": true,
+}
+
+// scrubbedHeaders
+// Заголовки, которые не сохраняются в кассету помимо заголовков с данными аутентификации:
+// длина тела меняется после замены значений.
+var scrubbedHeaders = map[string]bool{
+	"Content-Length": true,
+}
+
+// Request
+// Записанный запрос.
+type Request struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"`
+	Body   string `json:"body,omitempty"`
+}
+
+// Response
+// Записанный ответ.
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Interaction
+// Пара запрос/ответ.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette
+// Содержимое файла кассеты.
+type Cassette struct {
+	Source       string        `json:"source,omitempty"` // Откуда записаны ответы (см. WithSource).
+	Interactions []Interaction `json:"interactions"`
+}
+
+// UnmatchedError
+// Запрос, для которого в кассете нет записанного ответа.
+type UnmatchedError struct {
+	Request Request
+}
+
+func (e *UnmatchedError) Error() string {
+	return fmt.Sprintf("udscassette: no recorded interaction for %s %s?%s body=%s",
+		e.Request.Method, e.Request.Path, e.Request.Query, e.Request.Body)
+}
+
+// Recorder
+// http.RoundTripper, записывающий или воспроизводящий кассету.
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// RecorderOption
+// Функциональная опция для New и NewWithTransport
+type RecorderOption func(*Recorder)
+
+// WithSource
+// Описание сервера, с которого записывается кассета, например адрес песочницы UDS
+// или тестового сервера. Сохраняется в кассете при записи и не влияет на воспроизведение.
+func WithSource(source string) RecorderOption {
+	return func(r *Recorder) {
+		r.cassette.Source = source
+	}
+}
+
+// New
+// Создает транспорт для кассеты path. В режиме ModeReplay кассета должна существовать,
+// в режиме ModeRecord она будет перезаписана при вызове Stop.
+// Запросы при записи отправляются через http.DefaultTransport.
+func New(path string, mode Mode, opts ...RecorderOption) (*Recorder, error) {
+	return NewWithTransport(path, mode, http.DefaultTransport, opts...)
+}
+
+// NewWithTransport
+// Создает транспорт для кассеты path, отправляющий запросы при записи через transport.
+func NewWithTransport(path string, mode Mode, transport http.RoundTripper, opts ...RecorderOption) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode, transport: transport}
+
+	if mode == ModeRecord {
+		for _, opt := range opts {
+			opt(r)
+		}
+	}
+
+	if mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("udscassette: read cassette: %w", err)
+		}
+
+		if err = json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("udscassette: decode cassette %s: %w", path, err)
+		}
+
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+
+	return r, nil
+}
+
+// Source
+// Описание сервера, с которого записана кассета. Пустая строка, если оно не было задано при записи.
+func (r *Recorder) Source() string {
+	return r.cassette.Source
+}
+
+// HTTPClient
+// HTTP клиент, использующий транспорт.
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Option
+// Опция uds.NewClient, направляющая запросы клиента через транспорт.
+func (r *Recorder) Option() uds.Option {
+	return uds.WithHTTPClient(r.HTTPClient())
+}
+
+// Unused
+// Записанные взаимодействия, которые не были воспроизведены.
+// Позволяет убедиться, что тест выполнил все ожидаемые запросы.
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused []Interaction
+	for i, interaction := range r.cassette.Interactions {
+		if !r.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+// Check
+// Возвращает ошибку, если в кассете остались невоспроизведенные взаимодействия.
+func (r *Recorder) Check() error {
+	unused := r.Unused()
+	if len(unused) == 0 {
+		return nil
+	}
+
+	requests := make([]string, 0, len(unused))
+	for _, interaction := range unused {
+		requests = append(requests, interaction.Request.Method+" "+interaction.Request.Path)
+	}
+	sort.Strings(requests)
+
+	return errors.New("udscassette: unused interactions: " + strings.Join(requests, ", "))
+}
+
+// Stop
+// В режиме ModeRecord сохраняет кассету на диск.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, body, err := recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeRecord {
+		return r.record(req, recorded, body)
+	}
+
+	return r.replay(req, recorded)
+}
+
+func (r *Recorder) record(req *http.Request, recorded Request, body []byte) (*http.Response, error) {
+	// Тело исходного запроса уже прочитано, а сам запрос по контракту http.RoundTripper не изменяется,
+	// поэтому дальше отправляется копия с новым телом.
+	out := req.Clone(req.Context())
+	if body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+		out.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	resp, err := r.transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     scrubHeader(resp.Header),
+			Body:       redact.Body(respBody, redact.Keys),
+		},
+	})
+	r.mu.Unlock()
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || interaction.Request != recorded {
+			continue
+		}
+
+		r.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, &UnmatchedError{Request: recorded}
+}
+
+// recordRequest
+// Приводит запрос к виду, в котором он хранится в кассете и сопоставляется при воспроизведении.
+// Возвращает также исходное тело запроса.
+func recordRequest(req *http.Request) (Request, []byte, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return Request{}, nil, err
+		}
+		_ = req.Body.Close()
+	}
+
+	return Request{
+		Method: req.Method,
+		Path:   "/" + strings.TrimPrefix(req.URL.Path, "/"),
+		Query:  redact.Query(req.URL.Query(), redact.Keys).Encode(),
+		Body:   redact.Body(body, mergeKeys(redact.Keys, VolatileKeys)),
+	}, body, nil
+}
+
+func scrubHeader(header http.Header) http.Header {
+	scrubbedHeader := http.Header{}
+	for key, values := range header {
+		if canonical := http.CanonicalHeaderKey(key); !redact.Headers[canonical] && !scrubbedHeaders[canonical] {
+			scrubbedHeader[key] = append([]string(nil), values...)
+		}
+	}
+	return scrubbedHeader
+}
+
+func mergeKeys(sets ...map[string]bool) map[string]bool {
+	merged := map[string]bool{}
+	for _, set := range sets {
+		for key := range set {
+			merged[key] = true
+		}
+	}
+	return merged
+}
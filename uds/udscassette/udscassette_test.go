@@ -0,0 +1,141 @@
+package udscassette
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRecordScrubsSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Set-Cookie": {"session=secret"}, "Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"user":{"phone":"+79001234567"},"code":"654321"}`)),
+		}, nil
+	})
+
+	recorder, err := NewWithTransport(path, ModeRecord, transport)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "https://api.uds.app/partner/v2/operations?phone=%2B79001234567",
+		strings.NewReader(`{"code":"123456","nonce":"n-1","receipt":{"total":100}}`))
+	req.SetBasicAuth("company", "api-key")
+
+	resp, err := recorder.HTTPClient().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "654321") {
+		t.Errorf("caller got scrubbed response %s", body)
+	}
+
+	if err = recorder.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"79001234567", "123456", "654321", "n-1", "session=secret", "api-key", "Authorization"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q:\n%s", secret, data)
+		}
+	}
+}
+
+func TestReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	cassette := `{"source":"UDS sandbox","interactions":[{
+		"request":{"method":"POST","path":"/operations","query":"phone=%2A%2A%2A","body":"{\"code\":\"***\",\"nonce\":\"***\",\"receipt\":{\"total\":100}}"},
+		"response":{"statusCode":200,"body":"{\"id\":1}"}
+	}]}`
+	if err := os.WriteFile(path, []byte(cassette), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder, err := New(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := recorder.HTTPClient()
+
+	if source := recorder.Source(); source != "UDS sandbox" {
+		t.Errorf("Source = %q", source)
+	}
+
+	if err = recorder.Check(); err == nil {
+		t.Error("Check before replay: want unused interactions error")
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "http://udstest.invalid/operations?phone=%2B79000000000",
+		strings.NewReader(`{"receipt":{"total":100},"nonce":"other","code":"000000"}`))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != `{"id":1}` {
+		t.Errorf("replayed %d %s", resp.StatusCode, body)
+	}
+
+	if err = recorder.Check(); err != nil {
+		t.Error(err)
+	}
+
+	req, _ = http.NewRequest(http.MethodPost, "http://udstest.invalid/operations", strings.NewReader(`{"receipt":{"total":200}}`))
+	_, err = client.Do(req)
+
+	var unmatched *UnmatchedError
+	if !errors.As(err, &unmatched) || unmatched.Request.Path != "/operations" {
+		t.Errorf("unmatched request err = %v", err)
+	}
+}
+
+func TestRecordDoesNotModifyRequest(t *testing.T) {
+	var sent *http.Request
+	var sentBody []byte
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent = req
+		sentBody, _ = io.ReadAll(req.Body)
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{}`))}, nil
+	})
+
+	recorder, err := NewWithTransport(filepath.Join(t.TempDir(), "cassette.json"), ModeRecord, transport)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "https://api.uds.app/partner/v2/operations", strings.NewReader(`{"total":100}`))
+	body := req.Body
+
+	if _, err = recorder.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Body != body {
+		t.Error("RoundTrip replaced the body of the caller's request")
+	}
+	if sent == req {
+		t.Error("RoundTrip sent the caller's request instead of a copy")
+	}
+	if string(sentBody) != `{"total":100}` {
+		t.Errorf("sent body = %q", sentBody)
+	}
+}
@@ -0,0 +1,444 @@
+// Package udsmock
+// Настраиваемая реализация uds.API для модульных тестов кода, использующего клиента UDS.
+// Mock записывает все вызовы и возвращает результаты функций *Func,
+// заданных тестом. Если функция не задана, метод возвращает ErrNotConfigured.
+//
+//	mock := &udsmock.Mock{
+//		OperationCreateFunc: func(ctx context.Context, op *uds.CreateOperationRequest) (*uds.CreateOperationResponse, *resty.Response, error) {
+//			return nil, nil, udsmock.APIError(uds.ErrInsufficientFunds, "not enough points")
+//		},
+//	}
+package udsmock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/arcsub/go-uds/uds"
+	"github.com/go-resty/resty/v2"
+	"sync"
+)
+
+// ErrNotConfigured
+// Возвращается методом Mock, для которого не задана функция *Func.
+var ErrNotConfigured = errors.New("udsmock: method is not configured")
+
+func notConfigured(method string) error {
+	return fmt.Errorf("%w: %s", ErrNotConfigured, method)
+}
+
+// APIError
+// Ошибка API с кодом code для возврата из функций *Func.
+func APIError(code uds.ErrorCode, message string) *uds.ApiError {
+	return &uds.ApiError{ErrorCode: code, Message: message}
+}
+
+// Call
+// Записанный вызов метода Mock.
+// Вызовы методов с суффиксом WithContext и без него записываются под именем без суффикса.
+type Call struct {
+	Method string // Имя метода.
+	Args   []any  // Аргументы вызова без контекста.
+}
+
+// Mock
+// Реализация uds.API для тестов. Нулевое значение готово к использованию.
+// Функции *Func вызываются для методов как с суффиксом WithContext, так и без него.
+type Mock struct {
+	CustomerGetListFunc        func(ctx context.Context, maxValue int, offset int) (*uds.List[uds.Customer], *resty.Response, error)
+	CustomerFindByCodeFunc     func(ctx context.Context, code string, params *uds.FindCustomerParams) (*uds.FindCustomerResponse, *resty.Response, error)
+	CustomerFindByPhoneFunc    func(ctx context.Context, phone string, params *uds.FindCustomerParams) (*uds.FindCustomerResponse, *resty.Response, error)
+	CustomerFindByUIDFunc      func(ctx context.Context, uid string, params *uds.FindCustomerParams) (*uds.FindCustomerResponse, *resty.Response, error)
+	CustomerGetByIDFunc        func(ctx context.Context, id int64) (*uds.CustomerDetail, *resty.Response, error)
+	CustomerGetTagsFunc        func(ctx context.Context, id int64) (*uds.CustomerTagList, *resty.Response, error)
+	CustomerSetTagsFunc        func(ctx context.Context, id int64, tagsReq uds.SetCustomerTagsRequest) (*uds.List[uds.TagModel], *resty.Response, error)
+	OperationGetListFunc       func(ctx context.Context, maxValue int, cursor string) (*uds.OperationList, *resty.Response, error)
+	OperationCreateFunc        func(ctx context.Context, operation *uds.CreateOperationRequest) (*uds.CreateOperationResponse, *resty.Response, error)
+	OperationGetByIDFunc       func(ctx context.Context, id int64) (*uds.Operation, *resty.Response, error)
+	OperationRefundFunc        func(ctx context.Context, id int64, partialAmount float64) (*uds.Operation, *resty.Response, error)
+	OperationCalcFunc          func(ctx context.Context, operation *uds.CalcOperationRequest) (*uds.CalcOperationResponse, *resty.Response, error)
+	OperationRewardFunc        func(ctx context.Context, operation uds.RewardOperationRequest) (*uds.RewardOperationResponse, *resty.Response, error)
+	GoodsOrderGetByIDFunc      func(ctx context.Context, id int64) (*uds.GoodsOrderDetailed, *resty.Response, error)
+	GoodsOrderGetListFunc      func(ctx context.Context, maxValue int, offset int) (*uds.GoodsOrderList, *resty.Response, error)
+	GoodsOrderUpdateItemsFunc  func(ctx context.Context, id int64, updatedOrder *uds.UpdateGoodsOrderRequest[uds.GoodsOrderItemUpdate]) (*uds.GoodsOrderDetailed, *resty.Response, error)
+	GoodsOrderAddItemsFunc     func(ctx context.Context, id int64, updatedOrder *uds.UpdateGoodsOrderRequest[uds.GoodsOrderItemNew]) (*uds.GoodsOrderDetailed, *resty.Response, error)
+	GoodsOrderCompleteFunc     func(ctx context.Context, id int64) (*uds.CompleteGoodsOrder, *resty.Response, error)
+	GoodsOrderCancelFunc       func(ctx context.Context, id int64, reason string) (*uds.GoodsOrderDetailed, *resty.Response, error)
+	GoodsOrderSetStateFunc     func(ctx context.Context, id int64, state uds.GoodsOrderState) (*uds.GoodsOrderDetailed, *resty.Response, error)
+	GoodsOrderGenerateCodeFunc func(ctx context.Context, id int64) (string, *resty.Response, error)
+	GoodsGetListFunc           func(ctx context.Context, maxValue int, offset int, nodeId int64) (*uds.GoodsList, *resty.Response, error)
+	GoodsGetByIDFunc           func(ctx context.Context, id int64) (*uds.GoodsNode, *resty.Response, error)
+	GoodsCreateFunc            func(ctx context.Context, node *uds.GoodsNodeRequest) (*uds.GoodsNode, *resty.Response, error)
+	GoodsUpdateFunc            func(ctx context.Context, id int64, node *uds.GoodsNodeRequest) (*uds.GoodsNode, *resty.Response, error)
+	GoodsDeleteFunc            func(ctx context.Context, id int64) (*resty.Response, error)
+	SettingsGetFunc            func(ctx context.Context) (*uds.Settings, *resty.Response, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ uds.API = (*Mock)(nil)
+
+func (m *Mock) record(method string, args ...any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls
+// Все записанные вызовы в порядке их выполнения.
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo
+// Записанные вызовы метода method.
+func (m *Mock) CallsTo(method string) []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var calls []Call
+	for _, call := range m.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset
+// Удаляет записанные вызовы. Функции *Func не изменяются.
+func (m *Mock) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = nil
+}
+
+func (m *Mock) CustomerGetList(maxValue int, offset int) (*uds.List[uds.Customer], *resty.Response, error) {
+	return m.CustomerGetListWithContext(context.Background(), maxValue, offset)
+}
+
+func (m *Mock) CustomerGetListWithContext(ctx context.Context, maxValue int, offset int) (*uds.List[uds.Customer], *resty.Response, error) {
+	m.record("CustomerGetList", maxValue, offset)
+	if m.CustomerGetListFunc == nil {
+		return nil, nil, notConfigured("CustomerGetList")
+	}
+	return m.CustomerGetListFunc(ctx, maxValue, offset)
+}
+
+func (m *Mock) CustomerFindByCode(code string, params *uds.FindCustomerParams) (*uds.FindCustomerResponse, *resty.Response, error) {
+	return m.CustomerFindByCodeWithContext(context.Background(), code, params)
+}
+
+func (m *Mock) CustomerFindByCodeWithContext(ctx context.Context, code string, params *uds.FindCustomerParams) (*uds.FindCustomerResponse, *resty.Response, error) {
+	m.record("CustomerFindByCode", code, params)
+	if m.CustomerFindByCodeFunc == nil {
+		return nil, nil, notConfigured("CustomerFindByCode")
+	}
+	return m.CustomerFindByCodeFunc(ctx, code, params)
+}
+
+func (m *Mock) CustomerFindByPhone(phone string, params *uds.FindCustomerParams) (*uds.FindCustomerResponse, *resty.Response, error) {
+	return m.CustomerFindByPhoneWithContext(context.Background(), phone, params)
+}
+
+func (m *Mock) CustomerFindByPhoneWithContext(ctx context.Context, phone string, params *uds.FindCustomerParams) (*uds.FindCustomerResponse, *resty.Response, error) {
+	m.record("CustomerFindByPhone", phone, params)
+	if m.CustomerFindByPhoneFunc == nil {
+		return nil, nil, notConfigured("CustomerFindByPhone")
+	}
+	return m.CustomerFindByPhoneFunc(ctx, phone, params)
+}
+
+func (m *Mock) CustomerFindByUID(uid string, params *uds.FindCustomerParams) (*uds.FindCustomerResponse, *resty.Response, error) {
+	return m.CustomerFindByUIDWithContext(context.Background(), uid, params)
+}
+
+func (m *Mock) CustomerFindByUIDWithContext(ctx context.Context, uid string, params *uds.FindCustomerParams) (*uds.FindCustomerResponse, *resty.Response, error) {
+	m.record("CustomerFindByUID", uid, params)
+	if m.CustomerFindByUIDFunc == nil {
+		return nil, nil, notConfigured("CustomerFindByUID")
+	}
+	return m.CustomerFindByUIDFunc(ctx, uid, params)
+}
+
+func (m *Mock) CustomerGetByID(id int64) (*uds.CustomerDetail, *resty.Response, error) {
+	return m.CustomerGetByIDWithContext(context.Background(), id)
+}
+
+func (m *Mock) CustomerGetByIDWithContext(ctx context.Context, id int64) (*uds.CustomerDetail, *resty.Response, error) {
+	m.record("CustomerGetByID", id)
+	if m.CustomerGetByIDFunc == nil {
+		return nil, nil, notConfigured("CustomerGetByID")
+	}
+	return m.CustomerGetByIDFunc(ctx, id)
+}
+
+func (m *Mock) CustomerGetTags(id int64) (*uds.CustomerTagList, *resty.Response, error) {
+	return m.CustomerGetTagsWithContext(context.Background(), id)
+}
+
+func (m *Mock) CustomerGetTagsWithContext(ctx context.Context, id int64) (*uds.CustomerTagList, *resty.Response, error) {
+	m.record("CustomerGetTags", id)
+	if m.CustomerGetTagsFunc == nil {
+		return nil, nil, notConfigured("CustomerGetTags")
+	}
+	return m.CustomerGetTagsFunc(ctx, id)
+}
+
+func (m *Mock) CustomerSetTags(id int64, tagsReq uds.SetCustomerTagsRequest) (*uds.List[uds.TagModel], *resty.Response, error) {
+	return m.CustomerSetTagsWithContext(context.Background(), id, tagsReq)
+}
+
+func (m *Mock) CustomerSetTagsWithContext(ctx context.Context, id int64, tagsReq uds.SetCustomerTagsRequest) (*uds.List[uds.TagModel], *resty.Response, error) {
+	m.record("CustomerSetTags", id, tagsReq)
+	if m.CustomerSetTagsFunc == nil {
+		return nil, nil, notConfigured("CustomerSetTags")
+	}
+	return m.CustomerSetTagsFunc(ctx, id, tagsReq)
+}
+
+func (m *Mock) OperationGetList(maxValue int, cursor string) (*uds.OperationList, *resty.Response, error) {
+	return m.OperationGetListWithContext(context.Background(), maxValue, cursor)
+}
+
+func (m *Mock) OperationGetListWithContext(ctx context.Context, maxValue int, cursor string) (*uds.OperationList, *resty.Response, error) {
+	m.record("OperationGetList", maxValue, cursor)
+	if m.OperationGetListFunc == nil {
+		return nil, nil, notConfigured("OperationGetList")
+	}
+	return m.OperationGetListFunc(ctx, maxValue, cursor)
+}
+
+func (m *Mock) OperationCreate(operation *uds.CreateOperationRequest) (*uds.CreateOperationResponse, *resty.Response, error) {
+	return m.OperationCreateWithContext(context.Background(), operation)
+}
+
+func (m *Mock) OperationCreateWithContext(ctx context.Context, operation *uds.CreateOperationRequest) (*uds.CreateOperationResponse, *resty.Response, error) {
+	m.record("OperationCreate", operation)
+	if m.OperationCreateFunc == nil {
+		return nil, nil, notConfigured("OperationCreate")
+	}
+	return m.OperationCreateFunc(ctx, operation)
+}
+
+func (m *Mock) OperationGetByID(id int64) (*uds.Operation, *resty.Response, error) {
+	return m.OperationGetByIDWithContext(context.Background(), id)
+}
+
+func (m *Mock) OperationGetByIDWithContext(ctx context.Context, id int64) (*uds.Operation, *resty.Response, error) {
+	m.record("OperationGetByID", id)
+	if m.OperationGetByIDFunc == nil {
+		return nil, nil, notConfigured("OperationGetByID")
+	}
+	return m.OperationGetByIDFunc(ctx, id)
+}
+
+func (m *Mock) OperationRefund(id int64, partialAmount float64) (*uds.Operation, *resty.Response, error) {
+	return m.OperationRefundWithContext(context.Background(), id, partialAmount)
+}
+
+func (m *Mock) OperationRefundWithContext(ctx context.Context, id int64, partialAmount float64) (*uds.Operation, *resty.Response, error) {
+	m.record("OperationRefund", id, partialAmount)
+	if m.OperationRefundFunc == nil {
+		return nil, nil, notConfigured("OperationRefund")
+	}
+	return m.OperationRefundFunc(ctx, id, partialAmount)
+}
+
+func (m *Mock) OperationCalc(operation *uds.CalcOperationRequest) (*uds.CalcOperationResponse, *resty.Response, error) {
+	return m.OperationCalcWithContext(context.Background(), operation)
+}
+
+func (m *Mock) OperationCalcWithContext(ctx context.Context, operation *uds.CalcOperationRequest) (*uds.CalcOperationResponse, *resty.Response, error) {
+	m.record("OperationCalc", operation)
+	if m.OperationCalcFunc == nil {
+		return nil, nil, notConfigured("OperationCalc")
+	}
+	return m.OperationCalcFunc(ctx, operation)
+}
+
+func (m *Mock) OperationReward(operation uds.RewardOperationRequest) (*uds.RewardOperationResponse, *resty.Response, error) {
+	return m.OperationRewardWithContext(context.Background(), operation)
+}
+
+func (m *Mock) OperationRewardWithContext(ctx context.Context, operation uds.RewardOperationRequest) (*uds.RewardOperationResponse, *resty.Response, error) {
+	m.record("OperationReward", operation)
+	if m.OperationRewardFunc == nil {
+		return nil, nil, notConfigured("OperationReward")
+	}
+	return m.OperationRewardFunc(ctx, operation)
+}
+
+func (m *Mock) GoodsOrderGetByID(id int64) (*uds.GoodsOrderDetailed, *resty.Response, error) {
+	return m.GoodsOrderGetByIDWithContext(context.Background(), id)
+}
+
+func (m *Mock) GoodsOrderGetByIDWithContext(ctx context.Context, id int64) (*uds.GoodsOrderDetailed, *resty.Response, error) {
+	m.record("GoodsOrderGetByID", id)
+	if m.GoodsOrderGetByIDFunc == nil {
+		return nil, nil, notConfigured("GoodsOrderGetByID")
+	}
+	return m.GoodsOrderGetByIDFunc(ctx, id)
+}
+
+func (m *Mock) GoodsOrderGetList(maxValue int, offset int) (*uds.GoodsOrderList, *resty.Response, error) {
+	return m.GoodsOrderGetListWithContext(context.Background(), maxValue, offset)
+}
+
+func (m *Mock) GoodsOrderGetListWithContext(ctx context.Context, maxValue int, offset int) (*uds.GoodsOrderList, *resty.Response, error) {
+	m.record("GoodsOrderGetList", maxValue, offset)
+	if m.GoodsOrderGetListFunc == nil {
+		return nil, nil, notConfigured("GoodsOrderGetList")
+	}
+	return m.GoodsOrderGetListFunc(ctx, maxValue, offset)
+}
+
+func (m *Mock) GoodsOrderUpdateItems(id int64, updatedOrder *uds.UpdateGoodsOrderRequest[uds.GoodsOrderItemUpdate]) (*uds.GoodsOrderDetailed, *resty.Response, error) {
+	return m.GoodsOrderUpdateItemsWithContext(context.Background(), id, updatedOrder)
+}
+
+func (m *Mock) GoodsOrderUpdateItemsWithContext(ctx context.Context, id int64, updatedOrder *uds.UpdateGoodsOrderRequest[uds.GoodsOrderItemUpdate]) (*uds.GoodsOrderDetailed, *resty.Response, error) {
+	m.record("GoodsOrderUpdateItems", id, updatedOrder)
+	if m.GoodsOrderUpdateItemsFunc == nil {
+		return nil, nil, notConfigured("GoodsOrderUpdateItems")
+	}
+	return m.GoodsOrderUpdateItemsFunc(ctx, id, updatedOrder)
+}
+
+func (m *Mock) GoodsOrderAddItems(id int64, updatedOrder *uds.UpdateGoodsOrderRequest[uds.GoodsOrderItemNew]) (*uds.GoodsOrderDetailed, *resty.Response, error) {
+	return m.GoodsOrderAddItemsWithContext(context.Background(), id, updatedOrder)
+}
+
+func (m *Mock) GoodsOrderAddItemsWithContext(ctx context.Context, id int64, updatedOrder *uds.UpdateGoodsOrderRequest[uds.GoodsOrderItemNew]) (*uds.GoodsOrderDetailed, *resty.Response, error) {
+	m.record("GoodsOrderAddItems", id, updatedOrder)
+	if m.GoodsOrderAddItemsFunc == nil {
+		return nil, nil, notConfigured("GoodsOrderAddItems")
+	}
+	return m.GoodsOrderAddItemsFunc(ctx, id, updatedOrder)
+}
+
+func (m *Mock) GoodsOrderComplete(id int64) (*uds.CompleteGoodsOrder, *resty.Response, error) {
+	return m.GoodsOrderCompleteWithContext(context.Background(), id)
+}
+
+func (m *Mock) GoodsOrderCompleteWithContext(ctx context.Context, id int64) (*uds.CompleteGoodsOrder, *resty.Response, error) {
+	m.record("GoodsOrderComplete", id)
+	if m.GoodsOrderCompleteFunc == nil {
+		return nil, nil, notConfigured("GoodsOrderComplete")
+	}
+	return m.GoodsOrderCompleteFunc(ctx, id)
+}
+
+func (m *Mock) GoodsOrderCancel(id int64, reason string) (*uds.GoodsOrderDetailed, *resty.Response, error) {
+	return m.GoodsOrderCancelWithContext(context.Background(), id, reason)
+}
+
+func (m *Mock) GoodsOrderCancelWithContext(ctx context.Context, id int64, reason string) (*uds.GoodsOrderDetailed, *resty.Response, error) {
+	m.record("GoodsOrderCancel", id, reason)
+	if m.GoodsOrderCancelFunc == nil {
+		return nil, nil, notConfigured("GoodsOrderCancel")
+	}
+	return m.GoodsOrderCancelFunc(ctx, id, reason)
+}
+
+func (m *Mock) GoodsOrderSetState(id int64, state uds.GoodsOrderState) (*uds.GoodsOrderDetailed, *resty.Response, error) {
+	return m.GoodsOrderSetStateWithContext(context.Background(), id, state)
+}
+
+func (m *Mock) GoodsOrderSetStateWithContext(ctx context.Context, id int64, state uds.GoodsOrderState) (*uds.GoodsOrderDetailed, *resty.Response, error) {
+	m.record("GoodsOrderSetState", id, state)
+	if m.GoodsOrderSetStateFunc == nil {
+		return nil, nil, notConfigured("GoodsOrderSetState")
+	}
+	return m.GoodsOrderSetStateFunc(ctx, id, state)
+}
+
+func (m *Mock) GoodsOrderGenerateCode(id int64) (string, *resty.Response, error) {
+	return m.GoodsOrderGenerateCodeWithContext(context.Background(), id)
+}
+
+func (m *Mock) GoodsOrderGenerateCodeWithContext(ctx context.Context, id int64) (string, *resty.Response, error) {
+	m.record("GoodsOrderGenerateCode", id)
+	if m.GoodsOrderGenerateCodeFunc == nil {
+		return "", nil, notConfigured("GoodsOrderGenerateCode")
+	}
+	return m.GoodsOrderGenerateCodeFunc(ctx, id)
+}
+
+func (m *Mock) GoodsGetList(maxValue int, offset int, nodeId int64) (*uds.GoodsList, *resty.Response, error) {
+	return m.GoodsGetListWithContext(context.Background(), maxValue, offset, nodeId)
+}
+
+func (m *Mock) GoodsGetListWithContext(ctx context.Context, maxValue int, offset int, nodeId int64) (*uds.GoodsList, *resty.Response, error) {
+	m.record("GoodsGetList", maxValue, offset, nodeId)
+	if m.GoodsGetListFunc == nil {
+		return nil, nil, notConfigured("GoodsGetList")
+	}
+	return m.GoodsGetListFunc(ctx, maxValue, offset, nodeId)
+}
+
+func (m *Mock) GoodsGetByID(id int64) (*uds.GoodsNode, *resty.Response, error) {
+	return m.GoodsGetByIDWithContext(context.Background(), id)
+}
+
+func (m *Mock) GoodsGetByIDWithContext(ctx context.Context, id int64) (*uds.GoodsNode, *resty.Response, error) {
+	m.record("GoodsGetByID", id)
+	if m.GoodsGetByIDFunc == nil {
+		return nil, nil, notConfigured("GoodsGetByID")
+	}
+	return m.GoodsGetByIDFunc(ctx, id)
+}
+
+func (m *Mock) GoodsCreate(node *uds.GoodsNodeRequest) (*uds.GoodsNode, *resty.Response, error) {
+	return m.GoodsCreateWithContext(context.Background(), node)
+}
+
+func (m *Mock) GoodsCreateWithContext(ctx context.Context, node *uds.GoodsNodeRequest) (*uds.GoodsNode, *resty.Response, error) {
+	m.record("GoodsCreate", node)
+	if m.GoodsCreateFunc == nil {
+		return nil, nil, notConfigured("GoodsCreate")
+	}
+	return m.GoodsCreateFunc(ctx, node)
+}
+
+func (m *Mock) GoodsUpdate(id int64, node *uds.GoodsNodeRequest) (*uds.GoodsNode, *resty.Response, error) {
+	return m.GoodsUpdateWithContext(context.Background(), id, node)
+}
+
+func (m *Mock) GoodsUpdateWithContext(ctx context.Context, id int64, node *uds.GoodsNodeRequest) (*uds.GoodsNode, *resty.Response, error) {
+	m.record("GoodsUpdate", id, node)
+	if m.GoodsUpdateFunc == nil {
+		return nil, nil, notConfigured("GoodsUpdate")
+	}
+	return m.GoodsUpdateFunc(ctx, id, node)
+}
+
+func (m *Mock) GoodsDelete(id int64) (*resty.Response, error) {
+	return m.GoodsDeleteWithContext(context.Background(), id)
+}
+
+func (m *Mock) GoodsDeleteWithContext(ctx context.Context, id int64) (*resty.Response, error) {
+	m.record("GoodsDelete", id)
+	if m.GoodsDeleteFunc == nil {
+		return nil, notConfigured("GoodsDelete")
+	}
+	return m.GoodsDeleteFunc(ctx, id)
+}
+
+func (m *Mock) SettingsGet() (*uds.Settings, *resty.Response, error) {
+	return m.SettingsGetWithContext(context.Background())
+}
+
+func (m *Mock) SettingsGetWithContext(ctx context.Context) (*uds.Settings, *resty.Response, error) {
+	m.record("SettingsGet")
+	if m.SettingsGetFunc == nil {
+		return nil, nil, notConfigured("SettingsGet")
+	}
+	return m.SettingsGetFunc(ctx)
+}
@@ -0,0 +1,248 @@
+// Package udsprom
+// Реализация uds.Metrics, накапливающая метрики в памяти и отдающая их
+// в текстовом формате Prometheus без зависимости от клиентской библиотеки Prometheus.
+//
+//	collector := udsprom.New("uds")
+//	client := uds.NewClient(clientID, apiKey, uds.WithMetrics(collector))
+//	http.Handle("/metrics", collector)
+package udsprom
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/arcsub/go-uds/uds"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets
+// Границы корзин гистограммы времени выполнения запросов в секундах.
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// requestKey
+// Набор меток счетчика завершенных запросов.
+type requestKey struct {
+	operation string
+	status    int
+	code      uds.ErrorCode
+}
+
+// histogram
+// Накопленные значения гистограммы.
+type histogram struct {
+	counts []uint64 // Количество наблюдений в каждой корзине (не накопительно).
+	sum    float64
+	count  uint64
+}
+
+func (h *histogram) observe(buckets []float64, value float64) {
+	for i, bound := range buckets {
+		if value <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+// Collector
+// Коллектор метрик клиента UDS. Реализует uds.Metrics и http.Handler.
+type Collector struct {
+	namespace string
+	buckets   []float64
+
+	mu          sync.Mutex
+	inFlight    map[string]int64
+	requests    map[requestKey]uint64
+	durations   map[string]*histogram
+	retries     map[string]uint64
+	limiterWait map[string]*histogram
+}
+
+var _ uds.Metrics = (*Collector)(nil)
+
+// New
+// Создает коллектор с префиксом метрик namespace и корзинами DefaultBuckets.
+func New(namespace string) *Collector {
+	return NewWithBuckets(namespace, DefaultBuckets)
+}
+
+// NewWithBuckets
+// Создает коллектор с префиксом метрик namespace и границами корзин buckets в секундах.
+func NewWithBuckets(namespace string, buckets []float64) *Collector {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	return &Collector{
+		namespace:   namespace,
+		buckets:     sorted,
+		inFlight:    map[string]int64{},
+		requests:    map[requestKey]uint64{},
+		durations:   map[string]*histogram{},
+		retries:     map[string]uint64{},
+		limiterWait: map[string]*histogram{},
+	}
+}
+
+func (c *Collector) RequestStarted(operation string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inFlight[operation]++
+}
+
+func (c *Collector) RequestFinished(operation string, status int, code uds.ErrorCode, duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inFlight[operation]--
+	c.requests[requestKey{operation, status, code}]++
+	c.histogram(c.durations, operation).observe(c.buckets, duration.Seconds())
+}
+
+func (c *Collector) RequestRetried(operation string, _ int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.retries[operation]++
+}
+
+func (c *Collector) LimiterWaited(operation string, wait time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.histogram(c.limiterWait, operation).observe(c.buckets, wait.Seconds())
+}
+
+func (c *Collector) histogram(histograms map[string]*histogram, operation string) *histogram {
+	h, ok := histograms[operation]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(c.buckets))}
+		histograms[operation] = h
+	}
+	return h
+}
+
+// ServeHTTP
+// Отдает метрики в текстовом формате Prometheus.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = c.WriteTo(w)
+}
+
+// WriteTo
+// Записывает метрики в текстовом формате Prometheus.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	name := c.name("requests_in_flight")
+	cw.printf("# HELP %s Number of UDS requests in progress.\n# TYPE %s gauge\n", name, name)
+	for _, operation := range sortedKeys(c.inFlight) {
+		cw.printf("%s{operation=%s} %d\n", name, quote(operation), c.inFlight[operation])
+	}
+
+	name = c.name("requests_total")
+	cw.printf("# HELP %s Number of completed UDS requests.\n# TYPE %s counter\n", name, name)
+	keys := make([]requestKey, 0, len(c.requests))
+	for key := range c.requests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].operation != keys[j].operation {
+			return keys[i].operation < keys[j].operation
+		}
+		if keys[i].status != keys[j].status {
+			return keys[i].status < keys[j].status
+		}
+		return keys[i].code < keys[j].code
+	})
+	for _, key := range keys {
+		cw.printf("%s{operation=%s,status=%s,error_code=%s} %d\n",
+			name, quote(key.operation), quote(strconv.Itoa(key.status)), quote(string(key.code)), c.requests[key])
+	}
+
+	c.writeHistograms(cw, c.name("request_duration_seconds"), "Duration of UDS requests including retries.", c.durations)
+
+	name = c.name("retries_total")
+	cw.printf("# HELP %s Number of UDS request retries.\n# TYPE %s counter\n", name, name)
+	for _, operation := range sortedKeys(c.retries) {
+		cw.printf("%s{operation=%s} %d\n", name, quote(operation), c.retries[operation])
+	}
+
+	c.writeHistograms(cw, c.name("limiter_wait_seconds"), "Time spent waiting for the UDS rate limiter.", c.limiterWait)
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+
+	return cw.n, cw.err
+}
+
+func (c *Collector) writeHistograms(cw *countingWriter, name, help string, histograms map[string]*histogram) {
+	cw.printf("# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+
+	for _, operation := range sortedKeys(histograms) {
+		h := histograms[operation]
+		label := quote(operation)
+
+		var cumulative uint64
+		for i, bound := range c.buckets {
+			cumulative += h.counts[i]
+			cw.printf("%s_bucket{operation=%s,le=%s} %d\n",
+				name, label, quote(strconv.FormatFloat(bound, 'g', -1, 64)), cumulative)
+		}
+		cw.printf("%s_bucket{operation=%s,le=\"+Inf\"} %d\n", name, label, h.count)
+		cw.printf("%s_sum{operation=%s} %s\n", name, label, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		cw.printf("%s_count{operation=%s} %d\n", name, label, h.count)
+	}
+}
+
+func (c *Collector) name(metric string) string {
+	if c.namespace == "" {
+		return metric
+	}
+	return c.namespace + "_" + metric
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// quote
+// Значение метки в кавычках с экранированием по правилам текстового формата Prometheus.
+func quote(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
+
+// countingWriter
+// Считает записанные байты и запоминает первую ошибку записи.
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) printf(format string, args ...any) {
+	if cw.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(cw.w, format, args...)
+	cw.n += int64(n)
+	cw.err = err
+}
@@ -0,0 +1,514 @@
+// Package udsqueue
+// Очередь с упреждающей записью для OperationCreate и OperationReward, переживающая
+// падение процесса и отсутствие связи с UDS.
+//
+// Запрос сохраняется в Storage до отправки, Nonce операции фиксируется при постановке в очередь.
+// Каждая смена состояния записи сохраняется до обращения к API, поэтому после сбоя известно,
+// какие запросы могли дойти до UDS. Записи OperationCreate сверяются со списком операций (OperationGetList):
+// найденная операция отмечает запись выполненной, иначе запрос отправляется повторно.
+// Благодаря этому покупка не теряется и не проводится дважды.
+//
+// Операция начисления в списке не содержит ни комментария, ни другого признака запроса,
+// по которому ее можно надежно отличить от чужого начисления того же количества баллов.
+// Поэтому начисления с неизвестным результатом не отправляются повторно автоматически,
+// а переходят в StateReview и ждут решения через Resolve.
+//
+//	queue := udsqueue.New(client, udsqueue.NewFileStorage("/var/lib/pos/uds-queue"),
+//		udsqueue.WithCheckpointStore(uds.NewFileCheckpointStore("/var/lib/pos/uds-queue.cursor")))
+//	entry, err := queue.EnqueueCreate(ctx, request)
+//	...
+//	go queue.Run(ctx, 30*time.Second)
+package udsqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/arcsub/go-uds/uds"
+	"github.com/google/uuid"
+	"math"
+	"sync"
+	"time"
+)
+
+// Kind
+// Тип запроса в очереди.
+type Kind string
+
+const (
+	KindCreate Kind = "create" // OperationCreate.
+	KindReward Kind = "reward" // OperationReward.
+)
+
+// State
+// Состояние записи очереди.
+type State string
+
+const (
+	StatePending State = "pending" // Запрос ожидает отправки.
+	StateSending State = "sending" // Запрос отправлялся, но результат неизвестен. Перед повторной отправкой запись сверяется со списком операций.
+	StateDone    State = "done"    // Запрос выполнен.
+	StateFailed  State = "failed"  // API отклонило запрос, повторять его без изменений бессмысленно.
+	StateReview  State = "review"  // Начисление с неизвестным результатом, требуется ручная проверка (см. Resolve).
+)
+
+// DefaultClockSkew
+// Допустимое расхождение часов кассы и UDS при сверке операций по дате.
+const DefaultClockSkew = 5 * time.Minute
+
+// DefaultRunInterval
+// Интервал отправки очереди в Run, если передан interval <= 0.
+const DefaultRunInterval = 30 * time.Second
+
+// Entry
+// Запись очереди.
+type Entry struct {
+	ID           string                      `json:"id"`                     // Идентификатор записи. Для KindCreate совпадает с Nonce операции.
+	Seq          int64                       `json:"seq"`                    // Порядковый номер записи в очереди.
+	Kind         Kind                        `json:"kind"`                   // Тип запроса.
+	Create       *uds.CreateOperationRequest `json:"create,omitempty"`       // Запрос для KindCreate.
+	Reward       *uds.RewardOperationRequest `json:"reward,omitempty"`       // Запрос для KindReward.
+	State        State                       `json:"state"`                  // Состояние записи.
+	EnqueuedAt   time.Time                   `json:"enqueuedAt"`             // Время постановки в очередь.
+	SentAt       time.Time                   `json:"sentAt,omitempty"`       // Время первой отправки.
+	Attempts     int                         `json:"attempts"`               // Количество отправок.
+	LastError    string                      `json:"lastError,omitempty"`    // Последняя ошибка.
+	OperationIDs []int64                     `json:"operationIds,omitempty"` // ID операций UDS, созданных запросом.
+	Accepted     int                         `json:"accepted,omitempty"`     // Количество клиентов, которым начислены баллы (KindReward).
+}
+
+// Queue
+// Очередь запросов к API операций. Методы безопасны для вызова из нескольких горутин.
+type Queue struct {
+	api        uds.OperationsAPI
+	storage    Storage
+	checkpoint uds.CheckpointStore
+	clockSkew  time.Duration
+	hook       func(Entry)
+	errorHook  func(error)
+
+	mu sync.Mutex
+}
+
+// Option
+// Функциональная опция для New
+type Option func(*Queue)
+
+// WithCheckpointStore
+// Хранилище курсора списка операций, с которого начинается сверка.
+// Без него сверка каждый раз просматривает список операций с начала.
+func WithCheckpointStore(checkpoint uds.CheckpointStore) Option {
+	return func(q *Queue) {
+		q.checkpoint = checkpoint
+	}
+}
+
+// WithClockSkew
+// Допустимое расхождение часов кассы и UDS при сверке. По умолчанию DefaultClockSkew.
+func WithClockSkew(skew time.Duration) Option {
+	return func(q *Queue) {
+		q.clockSkew = skew
+	}
+}
+
+// WithHook
+// Функция, вызываемая после каждого сохранения записи.
+func WithHook(hook func(Entry)) Option {
+	return func(q *Queue) {
+		q.hook = hook
+	}
+}
+
+// WithErrorHook
+// Функция, получающая ошибки фоновой отправки в Run. Без нее ошибки, не прерывающие Run, не сообщаются.
+func WithErrorHook(hook func(error)) Option {
+	return func(q *Queue) {
+		q.errorHook = hook
+	}
+}
+
+// New
+// Создает очередь, отправляющую запросы через api и хранящую записи в storage.
+func New(api uds.OperationsAPI, storage Storage, opts ...Option) *Queue {
+	q := &Queue{
+		api:        api,
+		storage:    storage,
+		checkpoint: uds.NewMemoryCheckpointStore(""),
+		clockSkew:  DefaultClockSkew,
+	}
+
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	return q
+}
+
+// EnqueueCreate
+// Ставит в очередь проведение операции. Пустой Nonce заполняется новым UUID.
+// Повторная постановка запроса с тем же Nonce возвращает существующую запись.
+// Для операций, которые могут отправляться спустя долгое время, вместо кода на оплату
+// используйте Participant: код может истечь до отправки, и запись перейдет в StateFailed.
+func (q *Queue) EnqueueCreate(ctx context.Context, req uds.CreateOperationRequest) (Entry, error) {
+	if req.Nonce == "" {
+		req.Nonce = uuid.New().String()
+	}
+
+	return q.enqueue(ctx, Entry{ID: req.Nonce, Kind: KindCreate, Create: &req})
+}
+
+// EnqueueReward
+// Ставит в очередь начисление бонусов клиентам.
+func (q *Queue) EnqueueReward(ctx context.Context, req uds.RewardOperationRequest) (Entry, error) {
+	return q.enqueue(ctx, Entry{ID: uuid.New().String(), Kind: KindReward, Reward: &req})
+}
+
+// Entries
+// Все записи очереди в порядке постановки.
+func (q *Queue) Entries(ctx context.Context) ([]Entry, error) {
+	return q.storage.List(ctx)
+}
+
+// Remove
+// Удаляет выполненную или отклоненную запись. Записи в StateReview сначала разрешаются через Resolve.
+func (q *Queue) Remove(ctx context.Context, id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries, err := q.storage.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.ID == id && (entry.State == StatePending || entry.State == StateSending || entry.State == StateReview) {
+			return fmt.Errorf("udsqueue: entry %s is %s", id, entry.State)
+		}
+	}
+
+	return q.storage.Delete(ctx, id)
+}
+
+// Resolve
+// Разрешает запись в StateReview после ручной проверки списка операций клиентов:
+// delivered = true отмечает начисление выполненным, false возвращает запись в очередь на отправку.
+func (q *Queue) Resolve(ctx context.Context, id string, delivered bool) (Entry, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries, err := q.storage.List(ctx)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	for _, entry := range entries {
+		if entry.ID != id {
+			continue
+		}
+
+		if entry.State != StateReview {
+			return Entry{}, fmt.Errorf("udsqueue: entry %s is %s, not %s", id, entry.State, StateReview)
+		}
+
+		entry.LastError = ""
+		if delivered {
+			entry.State = StateDone
+		} else {
+			entry.State = StatePending
+		}
+
+		if err = q.put(ctx, entry); err != nil {
+			return Entry{}, err
+		}
+		return entry, nil
+	}
+
+	return Entry{}, fmt.Errorf("udsqueue: entry %s not found", id)
+}
+
+// Run
+// Отправляет записи очереди каждые interval, пока не будет отменен ctx, и возвращает ctx.Err().
+// Ошибки Replay не прерывают работу: очередь будет отправлена после восстановления связи,
+// а сами ошибки передаются в функцию из WithErrorHook.
+// Значение interval <= 0 означает DefaultRunInterval.
+func (q *Queue) Run(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		interval = DefaultRunInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := q.Replay(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			if q.errorHook != nil {
+				q.errorHook(err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Replay
+// Сверяет записи с неизвестным результатом со списком операций и отправляет ожидающие записи
+// в порядке постановки. Останавливается на первой ошибке связи (или ошибке сервера)
+// и возвращает ее, сохраняя порядок отправки. Записи, отклоненные API, переходят в StateFailed.
+func (q *Queue) Replay(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.reconcile(ctx); err != nil {
+		return err
+	}
+
+	entries, err := q.storage.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.State != StatePending {
+			continue
+		}
+
+		if err = q.send(ctx, entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (q *Queue) enqueue(ctx context.Context, entry Entry) (Entry, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries, err := q.storage.List(ctx)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	for _, existing := range entries {
+		if existing.ID == entry.ID {
+			return existing, nil
+		}
+		entry.Seq = max(entry.Seq, existing.Seq)
+	}
+
+	entry.Seq++
+	entry.State = StatePending
+	entry.EnqueuedAt = time.Now()
+
+	if err = q.put(ctx, entry); err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+// send
+// Отправляет запрос записи. Состояние StateSending сохраняется до обращения к API.
+func (q *Queue) send(ctx context.Context, entry Entry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	entry.State = StateSending
+	entry.Attempts++
+	if entry.SentAt.IsZero() {
+		entry.SentAt = time.Now()
+	}
+
+	if err := q.put(ctx, entry); err != nil {
+		return err
+	}
+
+	var err error
+	switch entry.Kind {
+	case KindCreate:
+		var resp *uds.CreateOperationResponse
+		if resp, _, err = q.api.OperationCreateWithContext(ctx, entry.Create); err == nil {
+			entry.OperationIDs = []int64{resp.Id}
+		}
+	case KindReward:
+		var resp *uds.RewardOperationResponse
+		if resp, _, err = q.api.OperationRewardWithContext(ctx, *entry.Reward); err == nil {
+			entry.Accepted = resp.Accepted
+		}
+	default:
+		err = fmt.Errorf("udsqueue: unknown entry kind %q", entry.Kind)
+	}
+
+	switch {
+	case err == nil:
+		entry.State = StateDone
+		entry.LastError = ""
+	case permanent(err):
+		entry.State = StateFailed
+		entry.LastError = err.Error()
+	default:
+		// Результат неизвестен: запись остается в StateSending и будет сверена перед повторной отправкой.
+		entry.LastError = err.Error()
+		if putErr := q.put(context.WithoutCancel(ctx), entry); putErr != nil {
+			return errors.Join(err, putErr)
+		}
+		return err
+	}
+
+	return q.put(context.WithoutCancel(ctx), entry)
+}
+
+// reconcile
+// Сверяет записи OperationCreate в состоянии StateSending с операциями, проведенными после курсора сверки.
+// Найденные записи отмечаются выполненными, остальные возвращаются в StatePending.
+// Начисления в состоянии StateSending переходят в StateReview.
+// Курсор сохраняется только после сохранения всех сверенных записей.
+func (q *Queue) reconcile(ctx context.Context) error {
+	entries, err := q.storage.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	claimed := map[int64]bool{}
+	var inDoubt []Entry
+	var since time.Time
+	for _, entry := range entries {
+		for _, id := range entry.OperationIDs {
+			claimed[id] = true
+		}
+
+		if entry.State != StateSending {
+			continue
+		}
+
+		if entry.Kind == KindReward {
+			entry.State = StateReview
+			entry.LastError = fmt.Sprintf("udsqueue: reward result is unknown (%s), check participants' operations and call Resolve", entry.LastError)
+			if err = q.put(ctx, entry); err != nil {
+				return err
+			}
+			continue
+		}
+
+		inDoubt = append(inDoubt, entry)
+		if since.IsZero() || entry.SentAt.Before(since) {
+			since = entry.SentAt
+		}
+	}
+
+	if len(inDoubt) == 0 {
+		return nil
+	}
+
+	cursor, err := q.checkpoint.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	scanned := uds.NewMemoryCheckpointStore(cursor)
+	stream := uds.NewOperationStream(q.api, uds.WithCheckpointStore(scanned),
+		uds.WithOperationFilter(uds.OperationFilter{From: since.Add(-q.clockSkew)}))
+
+	var operations []uds.Operation
+	for stream.Next(ctx) {
+		operations = append(operations, stream.Operation())
+	}
+	if err = stream.Err(); err != nil {
+		return err
+	}
+
+	for _, entry := range inDoubt {
+		ids := q.matchCreate(entry, operations, claimed)
+		if len(ids) > 0 {
+			entry.State = StateDone
+			entry.LastError = ""
+		} else {
+			entry.State = StatePending
+		}
+
+		entry.OperationIDs = ids
+		for _, id := range ids {
+			claimed[id] = true
+		}
+
+		if err = q.put(ctx, entry); err != nil {
+			return err
+		}
+	}
+
+	cursor, err = scanned.Load(ctx)
+	if err != nil {
+		return err
+	}
+	return q.checkpoint.Save(ctx, cursor)
+}
+
+// matchCreate
+// Операция, проведенная запросом: та же сумма чека, сумма деньгами, номер чека и клиент.
+// Без номера чека и UID клиента операцию нельзя отличить от чужой покупки на ту же сумму,
+// поэтому такая запись не сопоставляется и отправляется повторно с тем же Nonce,
+// по которому UDS вернет уже проведенную операцию.
+func (q *Queue) matchCreate(entry Entry, operations []uds.Operation, claimed map[int64]bool) []int64 {
+	req := entry.Create
+	if req.Receipt.Number == nil && (req.Participant == nil || req.Participant.Uid == nil) {
+		return nil
+	}
+
+	for _, operation := range operations {
+		if claimed[operation.Id] || !q.inWindow(entry, operation) {
+			continue
+		}
+
+		if !sameAmount(operation.Total, req.Receipt.Total) || !sameAmount(operation.Cash, req.Receipt.Cash) {
+			continue
+		}
+
+		if req.Receipt.Number != nil && operation.ReceiptNumber != *req.Receipt.Number {
+			continue
+		}
+
+		if req.Participant != nil && req.Participant.Uid != nil && operation.Customer.Uid != *req.Participant.Uid {
+			continue
+		}
+
+		return []int64{operation.Id}
+	}
+	return nil
+}
+
+// inWindow
+// Операция проведена не раньше первой отправки записи (с учетом расхождения часов)
+// и не является сторнирующей.
+func (q *Queue) inWindow(entry Entry, operation uds.Operation) bool {
+	return !operation.DateCreated.Before(entry.SentAt.Add(-q.clockSkew)) && operation.Origin.Id == 0
+}
+
+func (q *Queue) put(ctx context.Context, entry Entry) error {
+	if err := q.storage.Put(ctx, entry); err != nil {
+		return err
+	}
+
+	if q.hook != nil {
+		q.hook(entry)
+	}
+	return nil
+}
+
+// permanent
+// API отклонило запрос (4xx), и повторять его без изменений бессмысленно.
+// Ошибки аутентификации и превышение лимита запросов считаются временными.
+func permanent(err error) bool {
+	return uds.IsClientError(err) && !uds.IsRetryable(err) && !uds.IsAuthError(err)
+}
+
+func sameAmount(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
@@ -0,0 +1,175 @@
+package udsqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/arcsub/go-uds/uds/internal/fileutil"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Storage
+// Хранилище записей очереди. Put должен сохранять запись надежно (до возврата из метода),
+// так как очередь записывает изменения состояния до отправки запроса в API.
+// Кроме FileStorage и MemoryStorage интерфейс можно реализовать поверх bbolt, SQLite и т.п.
+type Storage interface {
+	// Put Добавляет запись или заменяет запись с тем же ID.
+	Put(ctx context.Context, entry Entry) error
+	// List Возвращает все записи в порядке Seq.
+	List(ctx context.Context) ([]Entry, error)
+	// Delete Удаляет запись. Удаление отсутствующей записи не является ошибкой.
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryStorage
+// Хранилище записей в памяти процесса. Не переживает перезапуск, подходит для тестов.
+type MemoryStorage struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+// NewMemoryStorage
+// Создает пустое хранилище в памяти.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{entries: map[string]Entry{}}
+}
+
+func (s *MemoryStorage) Put(_ context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[entry.ID] = cloneEntry(entry)
+	return nil
+}
+
+func (s *MemoryStorage) List(_ context.Context) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]Entry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, cloneEntry(entry))
+	}
+	sortEntries(entries)
+	return entries, nil
+}
+
+func (s *MemoryStorage) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, id)
+	return nil
+}
+
+// FileStorage
+// Хранилище записей в каталоге: по одному JSON файлу на запись.
+// Файл перезаписывается атомарно через временный файл и fsync,
+// поэтому после сбоя в нем остается либо старое, либо новое состояние записи.
+type FileStorage struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStorage
+// Создает хранилище в каталоге dir. Каталог создается при первой записи.
+func NewFileStorage(dir string) *FileStorage {
+	return &FileStorage{dir: dir}
+}
+
+func (s *FileStorage) Put(_ context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	path, err := s.path(entry.ID)
+	if err != nil {
+		return err
+	}
+
+	return fileutil.WriteAtomic(path, data)
+}
+
+func (s *FileStorage) List(_ context.Context) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var entry Entry
+		if err = json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("udsqueue: decode %s: %w", file.Name(), err)
+		}
+		entries = append(entries, entry)
+	}
+
+	sortEntries(entries)
+	return entries, nil
+}
+
+func (s *FileStorage) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	return fileutil.SyncDir(s.dir)
+}
+
+func (s *FileStorage) path(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		return "", fmt.Errorf("udsqueue: invalid entry id %q", id)
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
+
+func sortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Seq < entries[j].Seq
+	})
+}
+
+// cloneEntry
+// Копия записи, не разделяющая с исходной запросы и срезы.
+func cloneEntry(entry Entry) Entry {
+	data, _ := json.Marshal(entry)
+	var clone Entry
+	_ = json.Unmarshal(data, &clone)
+	return clone
+}
@@ -0,0 +1,147 @@
+package udstest
+
+import (
+	"github.com/arcsub/go-uds/uds"
+	"net/http"
+	"time"
+)
+
+func (s *Server) goodsList(w http.ResponseWriter, r *http.Request) {
+	maxValue, ok := queryInt(w, r, "max", 10)
+	if !ok {
+		return
+	}
+
+	offset, ok := queryInt(w, r, "offset", 0)
+	if !ok {
+		return
+	}
+
+	parentID, ok := queryInt(w, r, "nodeId", 0)
+	if !ok {
+		return
+	}
+
+	var children []uds.GoodsNode
+	for _, node := range s.goods {
+		if parentOf(node) == int64(parentID) {
+			children = append(children, *node)
+		}
+	}
+
+	list := uds.GoodsList{Rows: []uds.GoodsNode{}, Total: len(children)}
+	for i := offset; i < len(children) && i < offset+maxValue; i++ {
+		list.Rows = append(list.Rows, children[i])
+	}
+
+	writeJSON(w, list)
+}
+
+func (s *Server) goodsCreate(w http.ResponseWriter, r *http.Request) {
+	var req uds.GoodsNodeRequest
+	if !decode(w, r, &req) || !s.checkGoods(w, req, 0) {
+		return
+	}
+
+	if s.goodsLimit > 0 && len(s.goods) >= s.goodsLimit {
+		writeError(w, http.StatusBadRequest, &uds.ApiError{ErrorCode: uds.ErrGoodsLimitIsReached, Message: "Goods limit is reached"})
+		return
+	}
+
+	node := &uds.GoodsNode{
+		Id:          s.nextID(),
+		Name:        req.Name,
+		NodeId:      req.NodeId,
+		ExternalId:  req.ExternalId,
+		Hidden:      req.Hidden,
+		DateCreated: time.Now(),
+		Data:        req.Data,
+	}
+
+	s.goods = append(s.goods, node)
+	writeJSON(w, node)
+}
+
+func (s *Server) goodsUpdate(w http.ResponseWriter, r *http.Request, node *uds.GoodsNode) {
+	var req uds.GoodsNodeRequest
+	if !decode(w, r, &req) || !s.checkGoods(w, req, node.Id) {
+		return
+	}
+
+	node.Name = req.Name
+	node.NodeId = req.NodeId
+	node.ExternalId = req.ExternalId
+	node.Hidden = req.Hidden
+	node.Data = req.Data
+
+	writeJSON(w, node)
+}
+
+// goodsDelete
+// Удаляет элемент каталога вместе с вложенными элементами.
+func (s *Server) goodsDelete(w http.ResponseWriter, node *uds.GoodsNode) {
+	deleted := map[int64]bool{node.Id: true}
+	for changed := true; changed; {
+		changed = false
+		for _, child := range s.goods {
+			if !deleted[child.Id] && deleted[parentOf(child)] {
+				deleted[child.Id] = true
+				changed = true
+			}
+		}
+	}
+
+	goods := s.goods[:0]
+	for _, n := range s.goods {
+		if !deleted[n.Id] {
+			goods = append(goods, n)
+		}
+	}
+	s.goods = goods
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkGoods
+// Проверяет запрос на создание или изменение элемента id (0 - новый элемент).
+// Родителем может быть только существующая категория, не вложенная в сам элемент.
+func (s *Server) checkGoods(w http.ResponseWriter, req uds.GoodsNodeRequest, id int64) bool {
+	if req.Name == "" {
+		writeBadRequest(w, "name", req.Name, "must not be empty")
+		return false
+	}
+
+	switch req.Data.Type {
+	case uds.GoodsItemTypeItem, uds.GoodsItemTypeVaryingItem, uds.GoodsItemTypeCategory:
+	default:
+		writeBadRequest(w, "data.type", req.Data.Type, "unknown type")
+		return false
+	}
+
+	if req.Data.Type == uds.GoodsItemTypeVaryingItem && len(req.Data.Variants) == 0 {
+		writeBadRequest(w, "data.variants", nil, "must not be empty")
+		return false
+	}
+
+	if req.NodeId == nil {
+		return true
+	}
+
+	for parentID := *req.NodeId; parentID != 0; {
+		parent := s.goodsByID(parentID)
+		if parent == nil || parent.Data.Type != uds.GoodsItemTypeCategory || parent.Id == id {
+			writeError(w, http.StatusBadRequest, &uds.ApiError{ErrorCode: uds.ErrGoodsNodeIndexInvalid, Message: "Invalid node index"})
+			return false
+		}
+		parentID = parentOf(parent)
+	}
+
+	return true
+}
+
+func parentOf(node *uds.GoodsNode) int64 {
+	if node.NodeId == nil {
+		return 0
+	}
+	return *node.NodeId
+}
@@ -0,0 +1,868 @@
+package udstest
+
+import (
+	"encoding/json"
+	"github.com/arcsub/go-uds/uds"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// route
+// Сопоставляет запрос с методом method и шаблоном пути pattern, где * соответствует одному сегменту.
+// Возвращает значения сегментов *.
+func route(r *http.Request, method, pattern string) ([]string, bool) {
+	if r.Method != method {
+		return nil, false
+	}
+
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	parts := strings.Split(pattern, "/")
+	if len(path) != len(parts) {
+		return nil, false
+	}
+
+	var params []string
+	for i, part := range parts {
+		switch {
+		case part == "*":
+			params = append(params, path[i])
+		case part != path[i]:
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, password, ok := r.BasicAuth(); !ok || user != CompanyID || password != APIKey {
+		writeError(w, http.StatusUnauthorized, &uds.ApiError{ErrorCode: uds.ErrUnauthorized, Message: "Unauthorized"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := route(r, http.MethodGet, "settings"); ok {
+		writeJSON(w, s.settings)
+		return
+	}
+
+	if _, ok := route(r, http.MethodGet, "customers"); ok {
+		s.customerList(w, r)
+		return
+	}
+
+	if _, ok := route(r, http.MethodGet, "customers/find"); ok {
+		s.customerFind(w, r)
+		return
+	}
+
+	if params, ok := route(r, http.MethodGet, "customers/*"); ok {
+		s.withCustomer(w, params[0], s.customerGet)
+		return
+	}
+
+	if params, ok := route(r, http.MethodGet, "customers/*/tags"); ok {
+		s.withCustomer(w, params[0], s.customerTags)
+		return
+	}
+
+	if params, ok := route(r, http.MethodPost, "customers/*/tags"); ok {
+		s.withCustomer(w, params[0], func(w http.ResponseWriter, customer *uds.Customer) {
+			s.customerSetTags(w, r, customer)
+		})
+		return
+	}
+
+	if _, ok := route(r, http.MethodGet, "operations"); ok {
+		s.operationList(w, r)
+		return
+	}
+
+	if _, ok := route(r, http.MethodPost, "operations"); ok {
+		s.operationCreate(w, r)
+		return
+	}
+
+	if _, ok := route(r, http.MethodPost, "operations/calc"); ok {
+		s.operationCalc(w, r)
+		return
+	}
+
+	if _, ok := route(r, http.MethodPost, "operations/reward"); ok {
+		s.operationReward(w, r)
+		return
+	}
+
+	if params, ok := route(r, http.MethodGet, "operations/*"); ok {
+		s.withOperation(w, params[0], func(w http.ResponseWriter, operation *uds.Operation) {
+			writeJSON(w, operation)
+		})
+		return
+	}
+
+	if params, ok := route(r, http.MethodPost, "operations/*/refund"); ok {
+		s.withOperation(w, params[0], func(w http.ResponseWriter, operation *uds.Operation) {
+			s.operationRefund(w, r, operation)
+		})
+		return
+	}
+
+	if _, ok := route(r, http.MethodGet, "goods-orders"); ok {
+		s.goodsOrderList(w, r)
+		return
+	}
+
+	if params, ok := route(r, http.MethodGet, "goods-orders/*"); ok {
+		s.withGoodsOrder(w, params[0], func(w http.ResponseWriter, order *uds.GoodsOrderDetailed) {
+			writeJSON(w, order)
+		})
+		return
+	}
+
+	if params, ok := route(r, http.MethodPut, "goods-orders/*"); ok {
+		s.withGoodsOrder(w, params[0], func(w http.ResponseWriter, order *uds.GoodsOrderDetailed) {
+			s.goodsOrderUpdateItems(w, r, order)
+		})
+		return
+	}
+
+	if params, ok := route(r, http.MethodPost, "goods-orders/*/code"); ok {
+		s.withGoodsOrder(w, params[0], s.goodsOrderCode)
+		return
+	}
+
+	if params, ok := route(r, http.MethodPost, "goods-orders/*/complete"); ok {
+		s.withGoodsOrder(w, params[0], s.goodsOrderComplete)
+		return
+	}
+
+	if params, ok := route(r, http.MethodPost, "goods-orders/*/cancel"); ok {
+		s.withGoodsOrder(w, params[0], func(w http.ResponseWriter, order *uds.GoodsOrderDetailed) {
+			s.goodsOrderCancel(w, r, order)
+		})
+		return
+	}
+
+	if params, ok := route(r, http.MethodPut, "goods-orders/*/state"); ok {
+		s.withGoodsOrder(w, params[0], func(w http.ResponseWriter, order *uds.GoodsOrderDetailed) {
+			s.goodsOrderSetState(w, r, order)
+		})
+		return
+	}
+
+	if _, ok := route(r, http.MethodGet, "goods"); ok {
+		s.goodsList(w, r)
+		return
+	}
+
+	if _, ok := route(r, http.MethodPost, "goods"); ok {
+		s.goodsCreate(w, r)
+		return
+	}
+
+	if params, ok := route(r, http.MethodGet, "goods/*"); ok {
+		s.withGoods(w, params[0], func(w http.ResponseWriter, node *uds.GoodsNode) {
+			writeJSON(w, node)
+		})
+		return
+	}
+
+	if params, ok := route(r, http.MethodPut, "goods/*"); ok {
+		s.withGoods(w, params[0], func(w http.ResponseWriter, node *uds.GoodsNode) {
+			s.goodsUpdate(w, r, node)
+		})
+		return
+	}
+
+	if params, ok := route(r, http.MethodDelete, "goods/*"); ok {
+		s.withGoods(w, params[0], s.goodsDelete)
+		return
+	}
+
+	http.NotFound(w, r)
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, apiErr *uds.ApiError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(apiErr)
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, &uds.ApiError{ErrorCode: uds.ErrNotFound, Message: "Not found"})
+}
+
+func writeBadRequest(w http.ResponseWriter, field string, value any, message string) {
+	writeError(w, http.StatusBadRequest, &uds.ApiError{
+		ErrorCode: uds.ErrBadRequest,
+		Message:   "Validation failed",
+		Errors:    []uds.BadRequestError{fieldError(field, value, message)},
+	})
+}
+
+// decode
+// Разбирает тело запроса, при ошибке отвечает badRequest.
+func decode(w http.ResponseWriter, r *http.Request, value any) bool {
+	if err := json.NewDecoder(r.Body).Decode(value); err != nil {
+		writeBadRequest(w, "body", nil, err.Error())
+		return false
+	}
+	return true
+}
+
+// queryFloat
+// Числовой параметр запроса, 0 если он не указан.
+func queryFloat(w http.ResponseWriter, r *http.Request, name string) (float64, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return 0, true
+	}
+
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		writeBadRequest(w, name, raw, "must be a number")
+		return 0, false
+	}
+	return value, true
+}
+
+// queryInt
+// Неотрицательный целочисленный параметр запроса (max, offset, ID), def если он не указан.
+func queryInt(w http.ResponseWriter, r *http.Request, name string, def int) (int, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def, true
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		writeBadRequest(w, name, raw, "must be an integer")
+		return 0, false
+	}
+
+	if value < 0 {
+		writeBadRequest(w, name, raw, "must not be negative")
+		return 0, false
+	}
+	return value, true
+}
+
+func (s *Server) withCustomer(w http.ResponseWriter, rawID string, handle func(http.ResponseWriter, *uds.Customer)) {
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+
+	customer := s.customerByID(id)
+	if customer == nil {
+		writeNotFound(w)
+		return
+	}
+
+	handle(w, customer)
+}
+
+func (s *Server) withOperation(w http.ResponseWriter, rawID string, handle func(http.ResponseWriter, *uds.Operation)) {
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+
+	operation := s.operationByID(id)
+	if operation == nil {
+		writeNotFound(w)
+		return
+	}
+
+	handle(w, operation)
+}
+
+func (s *Server) withGoodsOrder(w http.ResponseWriter, rawID string, handle func(http.ResponseWriter, *uds.GoodsOrderDetailed)) {
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+
+	order, ok := s.orders[id]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	handle(w, order)
+}
+
+func (s *Server) withGoods(w http.ResponseWriter, rawID string, handle func(http.ResponseWriter, *uds.GoodsNode)) {
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+
+	node := s.goodsByID(id)
+	if node == nil {
+		writeNotFound(w)
+		return
+	}
+
+	handle(w, node)
+}
+
+func (s *Server) customerList(w http.ResponseWriter, r *http.Request) {
+	maxValue, ok := queryInt(w, r, "max", 10)
+	if !ok {
+		return
+	}
+
+	offset, ok := queryInt(w, r, "offset", 0)
+	if !ok {
+		return
+	}
+
+	customers := uds.List[uds.Customer]{Rows: []uds.Customer{}}
+	for i := offset; i < len(s.customers) && i < offset+maxValue; i++ {
+		customers.Rows = append(customers.Rows, *s.customers[i])
+	}
+
+	writeJSON(w, customers)
+}
+
+func (s *Server) customerFind(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var customer *uds.Customer
+	switch {
+	case query.Has("code"):
+		customer = s.customerByCode(query.Get("code"))
+	case query.Has("phone"):
+		customer = s.customerByPhone(query.Get("phone"))
+	case query.Has("uid"):
+		customer = s.customerByUID(query.Get("uid"))
+	default:
+		writeBadRequest(w, "code", nil, "one of code, phone or uid is required")
+		return
+	}
+
+	if customer == nil {
+		writeNotFound(w)
+		return
+	}
+
+	total, ok := queryFloat(w, r, "total")
+	if !ok {
+		return
+	}
+
+	skipLoyaltyTotal, ok := queryFloat(w, r, "skipLoyaltyTotal")
+	if !ok {
+		return
+	}
+
+	unredeemableTotal, ok := queryFloat(w, r, "unredeemableTotal")
+	if !ok {
+		return
+	}
+
+	resp := uds.FindCustomerResponse{
+		User:     s.customerDetail(customer),
+		Purchase: purchase(s.settings, customer.Participant, total, skipLoyaltyTotal, unredeemableTotal, nil),
+	}
+
+	if query.Get("exchangeCode") == "true" {
+		resp.Code = s.issueCode(customer.Participant.Id)
+	}
+
+	writeJSON(w, resp)
+}
+
+func (s *Server) customerDetail(customer *uds.Customer) uds.CustomerDetail {
+	return uds.CustomerDetail{Customer: *customer, Tags: s.tagsOf(customer)}
+}
+
+func (s *Server) tagsOf(customer *uds.Customer) []uds.TagModel {
+	tags := []uds.TagModel{}
+	for _, id := range s.customerTag[customer.Participant.Id] {
+		tags = append(tags, s.tags[id])
+	}
+	return tags
+}
+
+func (s *Server) customerGet(w http.ResponseWriter, customer *uds.Customer) {
+	writeJSON(w, s.customerDetail(customer))
+}
+
+func (s *Server) customerTags(w http.ResponseWriter, customer *uds.Customer) {
+	tags := s.tagsOf(customer)
+	writeJSON(w, uds.CustomerTagList{Rows: tags, Total: len(tags)})
+}
+
+func (s *Server) customerSetTags(w http.ResponseWriter, r *http.Request, customer *uds.Customer) {
+	var req uds.SetCustomerTagsRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	for _, id := range req.IDs {
+		if _, ok := s.tags[id]; !ok {
+			writeBadRequest(w, "ids", id, "unknown tag")
+			return
+		}
+	}
+
+	s.setTags(customer, req.IDs)
+	writeJSON(w, uds.List[uds.TagModel]{Rows: s.tagsOf(customer)})
+}
+
+func (s *Server) setTags(customer *uds.Customer, ids []int64) {
+	s.customerTag[customer.Participant.Id] = append([]int64(nil), ids...)
+}
+
+func shortInfo(customer *uds.Customer) uds.CustomerShortInfo {
+	return uds.CustomerShortInfo{
+		Id:             customer.Participant.Id,
+		DisplayName:    customer.DisplayName,
+		Uid:            customer.Uid,
+		MembershipTier: customer.Participant.MembershipTier,
+	}
+}
+
+// resolveCustomer
+// Находит клиента по коду на оплату или по данным participant.
+// Списание баллов без кода запрещено (withdrawNotPermitted).
+func (s *Server) resolveCustomer(w http.ResponseWriter, code *string, participant *uds.ParticipantShort, points float64) *uds.Customer {
+	var customer *uds.Customer
+
+	switch {
+	case code != nil:
+		customer = s.customerByCode(*code)
+	case participant != nil && (participant.Uid != nil || participant.Phone != nil):
+		if points != 0 {
+			writeError(w, http.StatusBadRequest, &uds.ApiError{ErrorCode: uds.ErrWithdrawNotPermitted, Message: "Withdraw not permitted"})
+			return nil
+		}
+
+		if participant.Uid != nil {
+			customer = s.customerByUID(*participant.Uid)
+		} else {
+			if !s.settings.PurchaseByPhone {
+				writeError(w, http.StatusBadRequest, &uds.ApiError{ErrorCode: uds.ErrPurchaseByPhoneDisabled, Message: "Purchase by phone disabled"})
+				return nil
+			}
+			customer = s.customerByPhone(*participant.Phone)
+		}
+	default:
+		writeBadRequest(w, "code", nil, "code or participant is required")
+		return nil
+	}
+
+	if customer == nil {
+		writeNotFound(w)
+		return nil
+	}
+
+	return customer
+}
+
+func (s *Server) operationList(w http.ResponseWriter, r *http.Request) {
+	maxValue, ok := queryInt(w, r, "max", 10)
+	if !ok {
+		return
+	}
+
+	cursor := r.URL.Query().Get("cursor")
+	var after int64
+	if cursor != "" {
+		var err error
+		if after, err = strconv.ParseInt(cursor, 10, 64); err != nil {
+			writeBadRequest(w, "cursor", cursor, "invalid cursor")
+			return
+		}
+	}
+
+	list := uds.OperationList{Rows: []uds.Operation{}, Cursor: cursor}
+	for _, operation := range s.operations {
+		if len(list.Rows) >= maxValue {
+			break
+		}
+		if operation.Id > after {
+			list.Rows = append(list.Rows, *operation)
+			list.Cursor = strconv.FormatInt(operation.Id, 10)
+		}
+	}
+
+	writeJSON(w, list)
+}
+
+func (s *Server) operationCreate(w http.ResponseWriter, r *http.Request) {
+	var req uds.CreateOperationRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if id, ok := s.nonces[req.Nonce]; ok && req.Nonce != "" {
+		writeJSON(w, uds.CreateOperationResponse(*s.operationByID(id)))
+		return
+	}
+
+	customer := s.resolveCustomer(w, req.Code, req.Participant, req.Receipt.Points)
+	if customer == nil {
+		return
+	}
+
+	if apiErr := checkReceipt(s.settings, customer.Participant, req.Receipt); apiErr != nil {
+		writeError(w, http.StatusBadRequest, apiErr)
+		return
+	}
+
+	t := tier(s.settings, customer.Participant)
+	earned := cashback(s.settings, t, req.Receipt.Total, valueOrZero(req.Receipt.SkipLoyaltyTotal), req.Receipt.Points)
+
+	operation := &uds.Operation{
+		Id:          s.nextID(),
+		DateCreated: time.Now(),
+		Action:      "PURCHASE",
+		State:       uds.ActionStateNormal,
+		Customer:    shortInfo(customer),
+		Points:      round2(earned - req.Receipt.Points),
+		Total:       req.Receipt.Total,
+		Cash:        req.Receipt.Cash,
+	}
+
+	if req.Receipt.Number != nil {
+		operation.ReceiptNumber = *req.Receipt.Number
+	}
+
+	if req.Cashier != nil {
+		operation.Cashier.DisplayName = req.Cashier.ExternalId
+		if req.Cashier.Name != nil {
+			operation.Cashier.DisplayName = *req.Cashier.Name
+		}
+	}
+
+	customer.Participant.Points = round2(customer.Participant.Points + operation.Points)
+	customer.Participant.LastTransactionTime = operation.DateCreated
+
+	if req.Tags != nil {
+		s.setTags(customer, req.Tags)
+	}
+
+	s.operations = append(s.operations, operation)
+	s.spent[operation.Id] = req.Receipt.Points
+	s.cashback[operation.Id] = earned
+	if req.Nonce != "" {
+		s.nonces[req.Nonce] = operation.Id
+	}
+
+	writeJSON(w, uds.CreateOperationResponse(*operation))
+}
+
+func (s *Server) operationCalc(w http.ResponseWriter, r *http.Request) {
+	var req uds.CalcOperationRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	customer := s.resolveCustomer(w, req.Code, req.Participant, 0)
+	if customer == nil {
+		return
+	}
+
+	receipt := req.Receipt
+	writeJSON(w, uds.CalcOperationResponse{
+		User:     shortInfo(customer),
+		Purchase: purchase(s.settings, customer.Participant, receipt.Total, valueOrZero(receipt.SkipLoyaltyTotal), valueOrZero(receipt.UnredeemableTotal), receipt.Points),
+	})
+}
+
+func (s *Server) operationRefund(w http.ResponseWriter, r *http.Request, origin *uds.Operation) {
+	var req uds.RefundOperationRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if origin.Action != "PURCHASE" || origin.State != uds.ActionStateNormal {
+		writeBadRequest(w, "id", origin.Id, "operation can not be refunded")
+		return
+	}
+
+	remaining := round2(origin.Total - s.refunded[origin.Id])
+	amount := req.PartialAmount
+	if amount == 0 {
+		amount = remaining
+	}
+
+	if amount < 0 || amount > remaining+epsilon/10 {
+		writeBadRequest(w, "partialAmount", req.PartialAmount, "must not exceed the remaining operation total")
+		return
+	}
+
+	customer := s.customerByID(origin.Customer.Id)
+	ratio := amount / origin.Total
+
+	refund := &uds.Operation{
+		Id:          s.nextID(),
+		DateCreated: time.Now(),
+		Action:      "REFUND",
+		State:       uds.ActionStateReversal,
+		Customer:    origin.Customer,
+		Cashier:     origin.Cashier,
+		Branch:      origin.Branch,
+		Points:      round2(floor2(s.spent[origin.Id]*ratio) - floor2(s.cashback[origin.Id]*ratio)),
+		Origin:      uds.Origin{Id: origin.Id},
+		Total:       amount,
+		Cash:        round2(origin.Cash * ratio),
+	}
+
+	s.refunded[origin.Id] = round2(s.refunded[origin.Id] + amount)
+	if s.refunded[origin.Id] >= origin.Total-epsilon/10 {
+		origin.State = uds.ActionStateCanceled
+	}
+
+	if customer != nil {
+		customer.Participant.Points = round2(customer.Participant.Points + refund.Points)
+	}
+
+	s.operations = append(s.operations, refund)
+	writeJSON(w, refund)
+}
+
+func (s *Server) operationReward(w http.ResponseWriter, r *http.Request) {
+	var req uds.RewardOperationRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	customers := make([]*uds.Customer, 0, len(req.Participants))
+	for _, id := range req.Participants {
+		customer := s.customerByID(id)
+		if customer == nil {
+			writeNotFound(w)
+			return
+		}
+
+		if customer.Participant.Points+req.Points < -epsilon/10 {
+			writeError(w, http.StatusBadRequest, &uds.ApiError{ErrorCode: uds.ErrInsufficientFunds, Message: "Insufficient funds"})
+			return
+		}
+
+		customers = append(customers, customer)
+	}
+
+	for _, customer := range customers {
+		customer.Participant.Points = round2(customer.Participant.Points + req.Points)
+		s.operations = append(s.operations, &uds.Operation{
+			Id:          s.nextID(),
+			DateCreated: time.Now(),
+			Action:      "REWARD",
+			State:       uds.ActionStateNormal,
+			Customer:    shortInfo(customer),
+			Points:      req.Points,
+		})
+	}
+
+	writeJSON(w, uds.RewardOperationResponse{Accepted: len(customers)})
+}
+
+func (s *Server) goodsOrderList(w http.ResponseWriter, r *http.Request) {
+	maxValue, ok := queryInt(w, r, "max", 10)
+	if !ok {
+		return
+	}
+
+	offset, ok := queryInt(w, r, "offset", 0)
+	if !ok {
+		return
+	}
+
+	orders := make([]uds.GoodsOrderDetailed, 0, len(s.orders))
+	for _, order := range s.orders {
+		orders = append(orders, *order)
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].DateCreated.Equal(orders[j].DateCreated) {
+			return orders[i].DateCreated.After(orders[j].DateCreated)
+		}
+		return orders[i].Id > orders[j].Id
+	})
+
+	list := uds.GoodsOrderList{Rows: []uds.GoodsOrderDetailed{}, Total: len(orders)}
+	for i := offset; i < len(orders) && i < offset+maxValue; i++ {
+		list.Rows = append(list.Rows, orders[i])
+	}
+
+	writeJSON(w, list)
+}
+
+// goodsOrderUpdateItems
+// Изменение товаров заказа: элемент с id изменяет количество товара заказа (0 - удаляет товар),
+// элемент без id добавляет новый товар. Сумма заказа пересчитывается с учетом стоимости доставки,
+// списываемые баллы ограничиваются новой суммой.
+func (s *Server) goodsOrderUpdateItems(w http.ResponseWriter, r *http.Request, order *uds.GoodsOrderDetailed) {
+	var req struct {
+		DeliveryCase uds.DeliveryCase `json:"deliveryCase"`
+		Items        []struct {
+			Id          int     `json:"id"`
+			ExternalId  string  `json:"externalId"`
+			Name        string  `json:"name"`
+			VariantName string  `json:"variantName"`
+			Qty         float64 `json:"qty"`
+			Price       float64 `json:"price"`
+		} `json:"items"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if order.State.Final() {
+		writeBadRequest(w, "state", order.State, "order can not be changed")
+		return
+	}
+
+	items := slices.Clone(order.Items)
+	for i, item := range req.Items {
+		field := "items[" + strconv.Itoa(i) + "]"
+
+		if item.Qty < 0 {
+			writeBadRequest(w, field+".qty", item.Qty, "must not be negative")
+			return
+		}
+
+		if item.Id == 0 {
+			if item.Name == "" || item.Price < 0 {
+				writeBadRequest(w, field, item.Name, "new item requires name and price")
+				return
+			}
+			items = append(items, uds.GoodOrderItem{
+				ExternalId:  item.ExternalId,
+				Name:        item.Name,
+				VariantName: item.VariantName,
+				Type:        uds.GoodsItemTypeItem,
+				Qty:         item.Qty,
+				Price:       item.Price,
+			})
+			continue
+		}
+
+		j := slices.IndexFunc(items, func(existing uds.GoodOrderItem) bool {
+			return existing.Id == item.Id && existing.VariantName == item.VariantName
+		})
+		if j < 0 {
+			writeBadRequest(w, field+".id", item.Id, "item not found in order")
+			return
+		}
+
+		if item.Qty == 0 {
+			items = slices.Delete(items, j, j+1)
+			continue
+		}
+		items[j].Qty = item.Qty
+	}
+
+	total := req.DeliveryCase.Value
+	for _, item := range items {
+		total += item.Price * item.Qty
+	}
+
+	order.Items = items
+	order.Total = round2(total)
+	order.Points = min(order.Points, order.Total)
+	order.Cash = round2(order.Total - order.Points - order.CertificatePoints)
+	writeJSON(w, order)
+}
+
+func (s *Server) goodsOrderCode(w http.ResponseWriter, order *uds.GoodsOrderDetailed) {
+	if order.State.Final() {
+		writeBadRequest(w, "state", order.State, "order can not be completed")
+		return
+	}
+
+	writeJSON(w, map[string]string{"code": s.issueCode(order.Customer.Id)})
+}
+
+func (s *Server) goodsOrderComplete(w http.ResponseWriter, order *uds.GoodsOrderDetailed) {
+	if !checkGoodsOrderTransition(w, order, uds.GoodsOrderStateCompleted) {
+		return
+	}
+
+	operation := &uds.Operation{
+		Id:          s.nextID(),
+		DateCreated: time.Now(),
+		Action:      "PURCHASE",
+		State:       uds.ActionStateNormal,
+		Customer:    order.Customer,
+		Branch:      order.Delivery.Branch,
+		Points:      -order.Points,
+		Total:       order.Total,
+		Cash:        order.Cash,
+	}
+
+	if customer := s.customerByID(order.Customer.Id); customer != nil {
+		customer.Participant.Points = round2(customer.Participant.Points - order.Points)
+	}
+
+	order.State = uds.GoodsOrderStateCompleted
+	s.operations = append(s.operations, operation)
+
+	var resp uds.CompleteGoodsOrder
+	resp.Transaction.Id = operation.Id
+	resp.Order = *order
+	writeJSON(w, resp)
+}
+
+func (s *Server) goodsOrderCancel(w http.ResponseWriter, r *http.Request, order *uds.GoodsOrderDetailed) {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if !decode(w, r, &req) || !checkGoodsOrderTransition(w, order, uds.GoodsOrderStateDeleted) {
+		return
+	}
+
+	order.State = uds.GoodsOrderStateDeleted
+	writeJSON(w, order)
+}
+
+func (s *Server) goodsOrderSetState(w http.ResponseWriter, r *http.Request, order *uds.GoodsOrderDetailed) {
+	var req struct {
+		State uds.GoodsOrderState `json:"state"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if req.State != uds.GoodsOrderStateNew && req.State != uds.GoodsOrderStateWaitingPayment {
+		writeBadRequest(w, "state", req.State, "state can not be set directly")
+		return
+	}
+
+	if !checkGoodsOrderTransition(w, order, req.State) {
+		return
+	}
+
+	order.State = req.State
+	writeJSON(w, order)
+}
+
+// checkGoodsOrderTransition
+// Проверяет переход заказа в статус to, при ошибке отвечает badRequest.
+func checkGoodsOrderTransition(w http.ResponseWriter, order *uds.GoodsOrderDetailed, to uds.GoodsOrderState) bool {
+	if err := uds.ValidateGoodsOrderTransition(order, to); err != nil {
+		writeBadRequest(w, "state", order.State, err.Error())
+		return false
+	}
+	return true
+}
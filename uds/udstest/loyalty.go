@@ -0,0 +1,172 @@
+package udstest
+
+import (
+	"github.com/arcsub/go-uds/uds"
+	"math"
+)
+
+// epsilon
+// Допустимая погрешность сравнения денежных сумм (половина копейки).
+const epsilon = 0.005
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// floor2
+// Округление баллов: только в меньшую сторону.
+func floor2(value float64) float64 {
+	return math.Floor(value*100+1e-9) / 100
+}
+
+// tier
+// Статус клиента, по которому рассчитывается скидка или кешбэк. Индивидуальный процент клиента
+// (DiscountRate при APPLY_DISCOUNT, CashbackRate при CHARGE_SCORES), если он задан, заменяет процент статуса.
+// Расчет намеренно не использует uds.CalculatePurchase, чтобы тесты против сервера проверяли калькулятор библиотеки.
+func tier(settings uds.Settings, participant uds.Participant) uds.MembershipTier {
+	t := participant.MembershipTier
+	if t.Uid == "" {
+		t = settings.LoyaltyProgramSettings.BaseMembershipTier
+	} else {
+		for _, candidate := range settings.LoyaltyProgramSettings.MembershipTiers {
+			if candidate.Uid == t.Uid {
+				t = candidate
+				break
+			}
+		}
+	}
+
+	rate := participant.CashbackRate
+	if settings.BaseDiscountPolicy == uds.DiscountPolicyApplyDiscount {
+		rate = participant.DiscountRate
+	}
+	if rate > 0 {
+		t.Rate = rate
+	}
+
+	return t
+}
+
+// amounts
+// Промежуточные суммы расчета операции.
+type amounts struct {
+	tier           uds.MembershipTier
+	discountAmount float64 // Скидка в денежных единицах (только APPLY_DISCOUNT).
+	pointsLimit    float64 // Сколько баллов допускается списать по настройкам статуса.
+	maxPoints      float64 // Сколько баллов клиент может списать с учетом баланса.
+}
+
+func calcAmounts(settings uds.Settings, participant uds.Participant, total, skipLoyaltyTotal, unredeemableTotal float64) amounts {
+	t := tier(settings, participant)
+	loyaltyTotal := max(0, total-skipLoyaltyTotal)
+
+	var discountAmount float64
+	if settings.BaseDiscountPolicy == uds.DiscountPolicyApplyDiscount {
+		discountAmount = round2(loyaltyTotal * t.Rate / 100)
+	}
+
+	redeemable := max(0, total-unredeemableTotal-discountAmount)
+	pointsLimit := floor2(redeemable * t.MaxScoresDiscount / 100)
+
+	return amounts{
+		tier:           t,
+		discountAmount: discountAmount,
+		pointsLimit:    pointsLimit,
+		maxPoints:      floor2(min(pointsLimit, max(0, participant.Points))),
+	}
+}
+
+// purchase
+// Рассчитывает операцию для клиента. Если points равно nil, списывается максимально доступное количество баллов.
+func purchase(settings uds.Settings, participant uds.Participant, total, skipLoyaltyTotal, unredeemableTotal float64, points *float64) uds.PurchaseDetail {
+	a := calcAmounts(settings, participant, total, skipLoyaltyTotal, unredeemableTotal)
+
+	spend := a.maxPoints
+	if points != nil {
+		spend = floor2(min(max(0, *points), a.maxPoints))
+	}
+
+	cash := round2(total - a.discountAmount - spend)
+	detail := uds.PurchaseDetail{
+		MaxPoints:         a.maxPoints,
+		Total:             total,
+		SkipLoyaltyTotal:  skipLoyaltyTotal,
+		UnredeemableTotal: unredeemableTotal,
+		DiscountAmount:    a.discountAmount,
+		Points:            spend,
+		NetDiscount:       round2(a.discountAmount + spend),
+		Cash:              cash,
+		CashTotal:         cash,
+		CashBack:          cashback(settings, a.tier, total, skipLoyaltyTotal, spend),
+		MaxScoresDiscount: a.tier.MaxScoresDiscount,
+	}
+
+	if settings.BaseDiscountPolicy == uds.DiscountPolicyApplyDiscount {
+		detail.DiscountPercent = a.tier.Rate
+	}
+
+	if total > 0 {
+		detail.PointsPercent = round2(spend / total * 100)
+		detail.NetDiscountPercent = round2(detail.NetDiscount / total * 100)
+	}
+
+	return detail
+}
+
+// cashback
+// Начисляемые баллы: процент статуса от части счета, оплаченной деньгами и участвующей в программе лояльности.
+func cashback(settings uds.Settings, t uds.MembershipTier, total, skipLoyaltyTotal, points float64) float64 {
+	if settings.BaseDiscountPolicy == uds.DiscountPolicyApplyDiscount {
+		return 0
+	}
+	return floor2(max(0, total-skipLoyaltyTotal-points) * t.Rate / 100)
+}
+
+// checkReceipt
+// Проверяет суммы чека по правилам, описанным для ErrInvalidChecksum,
+// ErrInsufficientFunds и ErrDiscountLimitExceed.
+func checkReceipt(settings uds.Settings, participant uds.Participant, receipt uds.Receipt) *uds.ApiError {
+	var fieldErrors []uds.BadRequestError
+	if receipt.Total <= 0 {
+		fieldErrors = append(fieldErrors, fieldError("receipt.total", receipt.Total, "must be greater than 0"))
+	}
+	if receipt.Cash < 0 {
+		fieldErrors = append(fieldErrors, fieldError("receipt.cash", receipt.Cash, "must not be negative"))
+	}
+	if receipt.Points < 0 {
+		fieldErrors = append(fieldErrors, fieldError("receipt.points", receipt.Points, "must not be negative"))
+	}
+	if len(fieldErrors) > 0 {
+		return &uds.ApiError{ErrorCode: uds.ErrBadRequest, Message: "Validation failed", Errors: fieldErrors}
+	}
+
+	skipLoyaltyTotal := valueOrZero(receipt.SkipLoyaltyTotal)
+	unredeemableTotal := valueOrZero(receipt.UnredeemableTotal)
+	a := calcAmounts(settings, participant, receipt.Total, skipLoyaltyTotal, unredeemableTotal)
+
+	expectedCash := round2(receipt.Total - a.discountAmount - receipt.Points)
+	if math.Abs(receipt.Cash-expectedCash) >= epsilon {
+		return &uds.ApiError{ErrorCode: uds.ErrInvalidChecksum, Message: "Invalid checksum"}
+	}
+
+	if receipt.Points > participant.Points+epsilon/10 {
+		return &uds.ApiError{ErrorCode: uds.ErrInsufficientFunds, Message: "Insufficient funds"}
+	}
+
+	if receipt.Points > a.pointsLimit+epsilon/10 {
+		return &uds.ApiError{ErrorCode: uds.ErrDiscountLimitExceed, Message: "Discount limit exceeded"}
+	}
+
+	return nil
+}
+
+func fieldError(field string, value any, message string) uds.BadRequestError {
+	return uds.BadRequestError{ErrorCode: "invalid", Message: message, Field: field, Value: value}
+}
+
+func valueOrZero(value *float64) float64 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
@@ -0,0 +1,370 @@
+// Package udstest
+// Фейковый сервер UDS partner API v2 для интеграционных тестов без доступа к сети.
+// Сервер хранит в памяти клиентов, их баланс, операции, заказы и каталог товаров и проверяет
+// суммы операций по правилам invalidChecksum, insufficientFunds и discountLimitExceed.
+//
+//	server := udstest.NewServer()
+//	defer server.Close()
+//
+//	customer := server.AddCustomer(uds.Customer{DisplayName: "Иван", Participant: uds.Participant{Points: 100}})
+//	code := server.IssueCode(customer.Participant.Id)
+//	client := server.Client()
+package udstest
+
+import (
+	"fmt"
+	"github.com/arcsub/go-uds/uds"
+	"github.com/google/uuid"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+const (
+	CompanyID = "549755813888"                         // ID компании, который принимает сервер.
+	APIKey    = "c2VjcmV0LWFwaS1rZXktZm9yLXVkc3Rlc3Q=" // API Key, который принимает сервер.
+)
+
+// Server
+// Фейковый сервер UDS. Методы безопасны для вызова из нескольких горутин,
+// в том числе во время обработки запросов.
+type Server struct {
+	*httptest.Server
+
+	mu sync.Mutex
+
+	settings    uds.Settings
+	customers   []*uds.Customer        // Клиенты в порядке добавления.
+	codes       map[string]int64       // Код на оплату -> ID клиента в компании.
+	tags        map[int64]uds.TagModel // Теги компании.
+	customerTag map[int64][]int64      // ID клиента -> ID тегов.
+	operations  []*uds.Operation       // Операции в порядке проведения.
+	nonces      map[string]int64       // Nonce -> ID операции.
+	spent       map[int64]float64      // ID операции -> списанные баллы.
+	cashback    map[int64]float64      // ID операции -> начисленные баллы.
+	refunded    map[int64]float64      // ID операции -> возвращенная сумма.
+	orders      map[int64]*uds.GoodsOrderDetailed
+	goods       []*uds.GoodsNode // Элементы каталога в порядке создания.
+	goodsLimit  int              // Лимит количества элементов каталога, 0 - без лимита.
+	lastID      int64
+}
+
+// NewServer
+// Запускает фейковый сервер с настройками DefaultSettings.
+func NewServer() *Server {
+	s := &Server{
+		settings:    DefaultSettings(),
+		codes:       map[string]int64{},
+		tags:        map[int64]uds.TagModel{},
+		customerTag: map[int64][]int64{},
+		nonces:      map[string]int64{},
+		spent:       map[int64]float64{},
+		cashback:    map[int64]float64{},
+		refunded:    map[int64]float64{},
+		orders:      map[int64]*uds.GoodsOrderDetailed{},
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// DefaultSettings
+// Настройки компании по умолчанию: кешбэк 5%, баллами можно оплатить до 50% счета.
+func DefaultSettings() uds.Settings {
+	tier := uds.MembershipTier{
+		Uid:               "base",
+		Name:              "Базовый",
+		Rate:              5,
+		MaxScoresDiscount: 50,
+	}
+
+	return uds.Settings{
+		Id:                 549755813888,
+		Name:               "udstest",
+		PromoCode:          "udstest",
+		Currency:           "RUB",
+		BaseDiscountPolicy: uds.DiscountPolicyChargeScores,
+		LoyaltyProgramSettings: uds.LoyaltyProgramSettings{
+			BaseMembershipTier: tier,
+			MembershipTiers:    []uds.MembershipTier{tier},
+		},
+		PurchaseByPhone: true,
+		Slug:            "udstest",
+	}
+}
+
+// Client
+// Клиент UDS, настроенный на работу с сервером. Повторные запросы отключены.
+func (s *Server) Client(opts ...uds.Option) *uds.Client {
+	opts = append([]uds.Option{
+		uds.WithBaseURL(s.URL),
+		uds.WithRetryPolicy(uds.NoRetryPolicy()),
+	}, opts...)
+
+	return uds.NewClient(CompanyID, APIKey, opts...)
+}
+
+// SetSettings
+// Заменяет настройки компании.
+func (s *Server) SetSettings(settings uds.Settings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.settings = settings
+}
+
+// Settings
+// Текущие настройки компании.
+func (s *Server) Settings() uds.Settings {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.settings
+}
+
+// AddCustomer
+// Добавляет клиента. Незаполненные UID, ID клиента в компании, статус и дата вступления
+// заполняются автоматически. Баланс задается в Participant.Points.
+func (s *Server) AddCustomer(customer uds.Customer) uds.Customer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if customer.Uid == "" {
+		customer.Uid = uuid.New().String()
+	}
+
+	if customer.Participant.Id == 0 {
+		customer.Participant.Id = s.nextID()
+	}
+
+	if customer.Participant.MembershipTier.Uid == "" {
+		customer.Participant.MembershipTier = s.settings.LoyaltyProgramSettings.BaseMembershipTier
+	}
+
+	if customer.Participant.DateCreated.IsZero() {
+		customer.Participant.DateCreated = time.Now()
+	}
+
+	if customer.Gender == "" {
+		customer.Gender = uds.GenderNotSpecified
+	}
+
+	s.customers = append(s.customers, &customer)
+	return customer
+}
+
+// Customer
+// Текущее состояние клиента по ID клиента в компании.
+func (s *Server) Customer(participantID int64) (uds.Customer, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	customer := s.customerByID(participantID)
+	if customer == nil {
+		return uds.Customer{}, false
+	}
+	return *customer, true
+}
+
+// IssueCode
+// Выдает клиенту код на оплату.
+func (s *Server) IssueCode(participantID int64) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.issueCode(participantID)
+}
+
+// ExpireCode
+// Делает код на оплату недействительным, как при истечении его времени жизни.
+func (s *Server) ExpireCode(code string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.codes, code)
+}
+
+// AddTag
+// Добавляет тег компании.
+func (s *Server) AddTag(name string) uds.TagModel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tag := uds.TagModel{Id: s.nextID(), Name: name}
+	s.tags[tag.Id] = tag
+	return tag
+}
+
+// Operations
+// Все проведенные операции в порядке проведения.
+func (s *Server) Operations() []uds.Operation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	operations := make([]uds.Operation, 0, len(s.operations))
+	for _, operation := range s.operations {
+		operations = append(operations, *operation)
+	}
+	return operations
+}
+
+// AddGoodsOrder
+// Добавляет заказ товаров. Незаполненные ID, дата и статус заполняются автоматически.
+func (s *Server) AddGoodsOrder(order uds.GoodsOrderDetailed) uds.GoodsOrderDetailed {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if order.Id == 0 {
+		order.Id = int(s.nextID())
+	}
+
+	if order.DateCreated.IsZero() {
+		order.DateCreated = time.Now()
+	}
+
+	if order.State == "" {
+		order.State = uds.GoodsOrderStateNew
+	}
+
+	s.orders[int64(order.Id)] = &order
+	return order
+}
+
+// GoodsOrder
+// Текущее состояние заказа товаров.
+func (s *Server) GoodsOrder(id int64) (uds.GoodsOrderDetailed, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[id]
+	if !ok {
+		return uds.GoodsOrderDetailed{}, false
+	}
+	return *order, true
+}
+
+// UpdateGoodsOrder
+// Изменяет заказ товаров, как если бы его изменил клиент или сотрудник в приложении UDS,
+// например меняет статус или отмечает онлайн-оплату. Возвращает false, если заказа нет.
+func (s *Server) UpdateGoodsOrder(id int64, update func(order *uds.GoodsOrderDetailed)) (uds.GoodsOrderDetailed, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[id]
+	if !ok {
+		return uds.GoodsOrderDetailed{}, false
+	}
+
+	update(order)
+	return *order, true
+}
+
+// AddGoods
+// Добавляет элемент каталога товаров без проверок. Незаполненные ID и дата заполняются автоматически.
+func (s *Server) AddGoods(node uds.GoodsNode) uds.GoodsNode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if node.Id == 0 {
+		node.Id = s.nextID()
+	}
+
+	if node.DateCreated.IsZero() {
+		node.DateCreated = time.Now()
+	}
+
+	s.goods = append(s.goods, &node)
+	return node
+}
+
+// Goods
+// Все элементы каталога товаров в порядке создания.
+func (s *Server) Goods() []uds.GoodsNode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	goods := make([]uds.GoodsNode, 0, len(s.goods))
+	for _, node := range s.goods {
+		goods = append(goods, *node)
+	}
+	return goods
+}
+
+// SetGoodsLimit
+// Лимит количества элементов каталога, при достижении которого GoodsCreate
+// возвращает ErrGoodsLimitIsReached. 0 - без лимита.
+func (s *Server) SetGoodsLimit(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.goodsLimit = limit
+}
+
+func (s *Server) nextID() int64 {
+	s.lastID++
+	return s.lastID
+}
+
+func (s *Server) issueCode(participantID int64) string {
+	for {
+		code := fmt.Sprintf("%06d", uuid.New().ID()%1000000)
+		if _, ok := s.codes[code]; !ok {
+			s.codes[code] = participantID
+			return code
+		}
+	}
+}
+
+func (s *Server) customerByID(participantID int64) *uds.Customer {
+	for _, customer := range s.customers {
+		if customer.Participant.Id == participantID {
+			return customer
+		}
+	}
+	return nil
+}
+
+func (s *Server) customerByUID(uid string) *uds.Customer {
+	for _, customer := range s.customers {
+		if customer.Uid == uid {
+			return customer
+		}
+	}
+	return nil
+}
+
+func (s *Server) customerByPhone(phone string) *uds.Customer {
+	for _, customer := range s.customers {
+		if customer.Phone == phone {
+			return customer
+		}
+	}
+	return nil
+}
+
+func (s *Server) customerByCode(code string) *uds.Customer {
+	participantID, ok := s.codes[code]
+	if !ok {
+		return nil
+	}
+	return s.customerByID(participantID)
+}
+
+func (s *Server) goodsByID(id int64) *uds.GoodsNode {
+	for _, node := range s.goods {
+		if node.Id == id {
+			return node
+		}
+	}
+	return nil
+}
+
+func (s *Server) operationByID(id int64) *uds.Operation {
+	for _, operation := range s.operations {
+		if operation.Id == id {
+			return operation
+		}
+	}
+	return nil
+}
@@ -0,0 +1,394 @@
+// Package udswebhook
+// Прием уведомлений UDS о заказах товаров и операциях: http.Handler, который проверяет
+// отправителя, разбирает тело уведомления в uds.GoodsOrderDetailed или uds.Operation
+// и вызывает соответствующий обработчик.
+//
+// Ответ 2xx подтверждает получение уведомления, на любой другой ответ UDS отправляет уведомление повторно.
+// Повторные доставки уже обработанного уведомления в пределах окна WithDedupWindow
+// подтверждаются без вызова обработчика.
+//
+// Отправитель проверяется через WithBasicAuth или WithSharedSecret. Без них все уведомления
+// отклоняются с кодом 401, если проверка явно не отключена через WithoutAuth.
+//
+//	handler := udswebhook.New(
+//		udswebhook.WithBasicAuth("uds", password),
+//		udswebhook.WithGoodsOrderHandler(func(ctx context.Context, order uds.GoodsOrderDetailed) error {
+//			return kitchen.Show(ctx, order)
+//		}),
+//	)
+//	http.Handle("/uds/webhook", handler)
+package udswebhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/arcsub/go-uds/uds"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultDedupWindow = 24 * time.Hour // Окно устранения повторных доставок по умолчанию.
+	DefaultMaxBodySize = 1 << 20        // Максимальный размер тела уведомления по умолчанию (1 МБ).
+)
+
+// GoodsOrderHandler
+// Обработчик уведомления о заказе товаров. Ошибка означает, что уведомление не обработано
+// и UDS должен отправить его повторно.
+type GoodsOrderHandler func(ctx context.Context, order uds.GoodsOrderDetailed) error
+
+// OperationHandler
+// Обработчик уведомления об операции. Ошибка означает, что уведомление не обработано
+// и UDS должен отправить его повторно.
+type OperationHandler func(ctx context.Context, operation uds.Operation) error
+
+// Handler
+// http.Handler для приема уведомлений UDS. Методы безопасны для вызова из нескольких горутин.
+type Handler struct {
+	basicUser     string
+	basicPassword string
+	secretHeader  string
+	secret        string
+	withoutAuth   bool
+	onGoodsOrder  GoodsOrderHandler
+	onOperation   OperationHandler
+	dedupWindow   time.Duration
+	maxBodySize   int64
+	logger        *slog.Logger
+
+	mu        sync.Mutex
+	delivered map[string]time.Time // Ключ уведомления -> время успешной обработки.
+	expiry    []deliveredKey       // Ключи из delivered в порядке обработки, для удаления по истечении окна.
+	inFlight  map[string]bool      // Ключи уведомлений, обрабатываемых в данный момент.
+}
+
+// deliveredKey
+// Ключ обработанного уведомления и время его обработки.
+type deliveredKey struct {
+	key string
+	at  time.Time
+}
+
+// Option
+// Функциональная опция для New
+type Option func(*Handler)
+
+// WithBasicAuth
+// Принимать уведомления только с заголовком Authorization: Basic с указанными именем и паролем.
+func WithBasicAuth(user, password string) Option {
+	return func(h *Handler) {
+		h.basicUser = user
+		h.basicPassword = password
+	}
+}
+
+// WithSharedSecret
+// Принимать уведомления только с заголовком header, равным secret.
+// Если заданы и WithBasicAuth, и WithSharedSecret, достаточно любого из способов.
+func WithSharedSecret(header, secret string) Option {
+	return func(h *Handler) {
+		h.secretHeader = http.CanonicalHeaderKey(header)
+		h.secret = secret
+	}
+}
+
+// WithoutAuth
+// Принимать уведомления без проверки отправителя, например, если она выполняется
+// обратным прокси перед обработчиком.
+func WithoutAuth() Option {
+	return func(h *Handler) {
+		h.withoutAuth = true
+	}
+}
+
+// WithGoodsOrderHandler
+// Обработчик уведомлений о заказах товаров. Без него такие уведомления подтверждаются без обработки.
+func WithGoodsOrderHandler(handler GoodsOrderHandler) Option {
+	return func(h *Handler) {
+		h.onGoodsOrder = handler
+	}
+}
+
+// WithOperationHandler
+// Обработчик уведомлений об операциях. Без него такие уведомления подтверждаются без обработки.
+func WithOperationHandler(handler OperationHandler) Option {
+	return func(h *Handler) {
+		h.onOperation = handler
+	}
+}
+
+// WithDedupWindow
+// Время, в течение которого повторная доставка обработанного уведомления не передается обработчику.
+// По умолчанию DefaultDedupWindow, 0 отключает устранение повторов.
+func WithDedupWindow(window time.Duration) Option {
+	return func(h *Handler) {
+		h.dedupWindow = max(0, window)
+	}
+}
+
+// WithMaxBodySize
+// Максимальный размер тела уведомления в байтах. По умолчанию DefaultMaxBodySize.
+func WithMaxBodySize(size int64) Option {
+	return func(h *Handler) {
+		if size > 0 {
+			h.maxBodySize = size
+		}
+	}
+}
+
+// WithLogger
+// Логгер отклоненных уведомлений и ошибок обработчиков.
+func WithLogger(logger *slog.Logger) Option {
+	return func(h *Handler) {
+		h.logger = logger
+	}
+}
+
+// New
+// Создает обработчик уведомлений. Без WithBasicAuth, WithSharedSecret и WithoutAuth
+// все уведомления отклоняются с кодом 401.
+func New(opts ...Option) *Handler {
+	h := &Handler{
+		dedupWindow: DefaultDedupWindow,
+		maxBodySize: DefaultMaxBodySize,
+		delivered:   map[string]time.Time{},
+		inFlight:    map[string]bool{},
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// ServeHTTP
+// Принимает уведомление. Коды ответа:
+//   - 200 - уведомление обработано, проигнорировано (нет обработчика) или уже было обработано;
+//   - 400 - тело не является уведомлением о заказе или операции;
+//   - 401 - отправитель не прошел проверку или способ проверки не задан;
+//   - 405 - метод запроса отличается от POST;
+//   - 409 - то же уведомление обрабатывается в данный момент;
+//   - 413 - тело больше WithMaxBodySize;
+//   - 500 - обработчик вернул ошибку.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		h.reject(w, r, http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	if !h.authorized(r) {
+		if h.basicUser != "" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="udswebhook"`)
+		}
+		h.reject(w, r, http.StatusUnauthorized, nil)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodySize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.reject(w, r, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		h.reject(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	notification, err := decode(body)
+	if err != nil {
+		h.reject(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	switch h.begin(notification.key) {
+	case deliveryDuplicate:
+		w.WriteHeader(http.StatusOK)
+		return
+	case deliveryInFlight:
+		h.reject(w, r, http.StatusConflict, nil)
+		return
+	}
+
+	if err = h.dispatch(r.Context(), notification); err != nil {
+		h.finish(notification.key, false)
+		h.reject(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.finish(notification.key, true)
+	w.WriteHeader(http.StatusOK)
+}
+
+// authorized
+// Проверяет отправителя уведомления.
+func (h *Handler) authorized(r *http.Request) bool {
+	if h.basicUser == "" && h.secretHeader == "" {
+		return h.withoutAuth
+	}
+
+	if h.basicUser != "" {
+		if user, password, ok := r.BasicAuth(); ok && equal(user, h.basicUser) && equal(password, h.basicPassword) {
+			return true
+		}
+	}
+
+	if h.secretHeader != "" {
+		if secret := r.Header.Get(h.secretHeader); secret != "" && equal(secret, h.secret) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *Handler) dispatch(ctx context.Context, n notification) error {
+	switch {
+	case n.goodsOrder != nil && h.onGoodsOrder != nil:
+		return h.onGoodsOrder(ctx, *n.goodsOrder)
+	case n.operation != nil && h.onOperation != nil:
+		return h.onOperation(ctx, *n.operation)
+	}
+	return nil
+}
+
+// delivery
+// Результат проверки уведомления на повторную доставку.
+type delivery int
+
+const (
+	deliveryNew       delivery = iota // Уведомление нужно обработать.
+	deliveryDuplicate                 // Уведомление уже обработано в пределах окна.
+	deliveryInFlight                  // Уведомление обрабатывается в данный момент.
+)
+
+// begin
+// Отмечает уведомление как обрабатываемое, если оно не обработано ранее и не обрабатывается сейчас.
+func (h *Handler) begin(key string) delivery {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.prune(time.Now())
+
+	if _, ok := h.delivered[key]; ok {
+		return deliveryDuplicate
+	}
+
+	if h.inFlight[key] {
+		return deliveryInFlight
+	}
+
+	h.inFlight[key] = true
+	return deliveryNew
+}
+
+func (h *Handler) finish(key string, delivered bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.inFlight, key)
+	if delivered && h.dedupWindow > 0 {
+		now := time.Now()
+		h.delivered[key] = now
+		h.expiry = append(h.expiry, deliveredKey{key: key, at: now})
+	}
+}
+
+// prune
+// Удаляет ключи, окно которых истекло. Ключи в expiry упорядочены по времени обработки,
+// поэтому просматриваются только истекшие.
+func (h *Handler) prune(now time.Time) {
+	n := 0
+	for n < len(h.expiry) && now.Sub(h.expiry[n].at) >= h.dedupWindow {
+		if expired := h.expiry[n]; h.delivered[expired.key].Equal(expired.at) {
+			delete(h.delivered, expired.key)
+		}
+		n++
+	}
+
+	if n > 0 {
+		clear(h.expiry[:n])
+		h.expiry = h.expiry[n:]
+	}
+}
+
+func (h *Handler) reject(w http.ResponseWriter, r *http.Request, status int, err error) {
+	if h.logger != nil {
+		attrs := []any{slog.String("path", r.URL.Path), slog.Int("status", status)}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+
+		level := slog.LevelWarn
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		h.logger.Log(r.Context(), level, "udswebhook: notification rejected", attrs...)
+	}
+
+	http.Error(w, http.StatusText(status), status)
+}
+
+// notification
+// Разобранное уведомление: заполнено одно из полей goodsOrder и operation.
+type notification struct {
+	key        string // Ключ устранения повторов.
+	goodsOrder *uds.GoodsOrderDetailed
+	operation  *uds.Operation
+}
+
+// decode
+// Определяет тип уведомления по полям тела: у операции есть action, у заказа - items или delivery.
+// Ключ устранения повторов включает статус, поэтому уведомление об изменении статуса
+// того же заказа или операции не считается повторной доставкой.
+func decode(body []byte) (notification, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return notification{}, fmt.Errorf("udswebhook: decode notification: %w", err)
+	}
+
+	if _, ok := fields["id"]; !ok {
+		return notification{}, errors.New("udswebhook: notification has no id")
+	}
+
+	_, isOperation := fields["action"]
+	_, hasItems := fields["items"]
+	_, hasDelivery := fields["delivery"]
+
+	switch {
+	case isOperation:
+		operation := new(uds.Operation)
+		if err := json.Unmarshal(body, operation); err != nil {
+			return notification{}, fmt.Errorf("udswebhook: decode operation: %w", err)
+		}
+		return notification{
+			key:       fmt.Sprintf("operation:%d:%s", operation.Id, operation.State),
+			operation: operation,
+		}, nil
+	case hasItems || hasDelivery:
+		order := new(uds.GoodsOrderDetailed)
+		if err := json.Unmarshal(body, order); err != nil {
+			return notification{}, fmt.Errorf("udswebhook: decode goods order: %w", err)
+		}
+		return notification{
+			key:        fmt.Sprintf("goods-order:%d:%s:%t", order.Id, order.State, order.OnlinePayment.Completed),
+			goodsOrder: order,
+		}, nil
+	}
+
+	return notification{}, errors.New("udswebhook: unknown notification type")
+}
+
+// equal
+// Сравнение секретов за время, не зависящее от совпадающей части.
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}